@@ -0,0 +1,322 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bambocher/golog"
+)
+
+var handlerPath = regexp.MustCompile(`^(.+)/handlers/(\d+)$`)
+
+type LevelInfo struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
+type HandlerInfo struct {
+	Index      int       `json:"index"`
+	Type       string    `json:"type"`
+	Level      LevelInfo `json:"level"`
+	Format     string    `json:"format,omitempty"`
+	DateFormat string    `json:"dateFormat,omitempty"`
+	Override   *Override `json:"override,omitempty"`
+}
+
+type LoggerInfo struct {
+	Name     string        `json:"name"`
+	Level    string        `json:"level"`
+	Records  uint64        `json:"records"`
+	Handlers []HandlerInfo `json:"handlers"`
+	Override *Override     `json:"override,omitempty"`
+}
+
+type Override struct {
+	Expires time.Time `json:"expires"`
+	timer   *time.Timer
+	revert  func()
+}
+
+type change struct {
+	Level string `json:"level"`
+	Min   string `json:"min"`
+	Max   string `json:"max"`
+	TTL   string `json:"ttl"`
+}
+
+type Handler struct {
+	sync.Mutex
+	registry  *golog.Registry
+	overrides map[interface{}]*Override
+}
+
+func (handler *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	path := strings.Trim(request.URL.Path, "/")
+
+	if len(path) <= 0 {
+		if request.Method != "GET" {
+			methodNotAllowed(writer, "GET")
+			return
+		}
+
+		loggers := []LoggerInfo{}
+		for _, logger := range handler.registry.Loggers() {
+			loggers = append(loggers, handler.loggerInfo(logger))
+		}
+		respond(writer, loggers)
+		return
+	}
+
+	if match := handlerPath.FindStringSubmatch(path); match != nil {
+		if logger, ok := handler.findLogger(match[1]); ok {
+			index, _ := strconv.Atoi(match[2])
+			handler.serveHandler(writer, request, logger, index)
+			return
+		}
+	}
+
+	logger, ok := handler.findLogger(path)
+	if !ok {
+		http.Error(writer, fmt.Sprintf("Logger [%s] not found", path), http.StatusNotFound)
+		return
+	}
+
+	handler.serveLogger(writer, request, logger)
+}
+
+func (handler *Handler) serveLogger(writer http.ResponseWriter, request *http.Request, logger *golog.Logger) {
+	switch request.Method {
+	case "GET":
+	case "PUT", "PATCH":
+		body, ttl, ok := decodeChange(writer, request)
+		if !ok {
+			return
+		}
+
+		level, err := golog.ParseLevel(body.Level)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		previous := logger.GetLevel()
+		handler.override(logger, ttl, func() {
+			logger.SetLevel(previous)
+		})
+		logger.SetLevel(level)
+	default:
+		methodNotAllowed(writer, "GET, PUT, PATCH")
+		return
+	}
+
+	respond(writer, handler.loggerInfo(logger))
+}
+
+func (handler *Handler) serveHandler(writer http.ResponseWriter, request *http.Request, logger *golog.Logger, index int) {
+	handlers := logger.GetHandlers()
+	if index >= len(handlers) {
+		http.Error(writer, fmt.Sprintf("Handler [%d] not found for logger [%s]", index, logger.GetName()), http.StatusNotFound)
+		return
+	}
+	target := handlers[index]
+
+	switch request.Method {
+	case "GET":
+	case "PUT", "PATCH":
+		body, ttl, ok := decodeChange(writer, request)
+		if !ok {
+			return
+		}
+
+		level := target.GetLevel()
+		if len(body.Min) > 0 {
+			min, err := golog.ParseLevel(body.Min)
+			if err != nil {
+				http.Error(writer, err.Error(), http.StatusBadRequest)
+				return
+			}
+			level.Min = min
+		}
+		if len(body.Max) > 0 {
+			max, err := golog.ParseLevel(body.Max)
+			if err != nil {
+				http.Error(writer, err.Error(), http.StatusBadRequest)
+				return
+			}
+			level.Max = max
+		}
+		if level.Min > level.Max {
+			http.Error(writer, "Level min is above max", http.StatusBadRequest)
+			return
+		}
+
+		previous := target.GetLevel()
+		handler.override(target, ttl, func() {
+			target.SetLevel(previous)
+		})
+		target.SetLevel(level)
+	default:
+		methodNotAllowed(writer, "GET, PUT, PATCH")
+		return
+	}
+
+	respond(writer, handler.handlerInfo(index, target))
+}
+
+func (handler *Handler) override(key interface{}, ttl time.Duration, revert func()) {
+	handler.Lock()
+	defer handler.Unlock()
+
+	override, ok := handler.overrides[key]
+	if ok {
+		override.timer.Stop()
+		delete(handler.overrides, key)
+		revert = override.revert
+	}
+
+	if ttl <= 0 {
+		return
+	}
+
+	override = &Override{Expires: time.Now().Add(ttl), revert: revert}
+	override.timer = time.AfterFunc(ttl, func() {
+		handler.Lock()
+		current := handler.overrides[key] == override
+		if current {
+			delete(handler.overrides, key)
+		}
+		handler.Unlock()
+
+		if current {
+			revert()
+		}
+	})
+	handler.overrides[key] = override
+}
+
+func (handler *Handler) loggerInfo(logger *golog.Logger) LoggerInfo {
+	info := LoggerInfo{
+		Name:     logger.GetName(),
+		Level:    golog.LevelToString(logger.GetLevel()),
+		Records:  logger.GetRecordCount(),
+		Handlers: []HandlerInfo{},
+	}
+
+	for index, target := range logger.GetHandlers() {
+		info.Handlers = append(info.Handlers, handler.handlerInfo(index, target))
+	}
+
+	handler.Lock()
+	info.Override = handler.overrides[logger]
+	handler.Unlock()
+
+	return info
+}
+
+func (handler *Handler) handlerInfo(index int, target golog.Handler) HandlerInfo {
+	level := target.GetLevel()
+	info := HandlerInfo{
+		Index: index,
+		Type:  handlerType(target),
+		Level: LevelInfo{golog.LevelToString(level.Min), golog.LevelToString(level.Max)},
+	}
+
+	if formatter := target.GetFormatter(); formatter != nil {
+		info.Format = formatter.GetFormat()
+		info.DateFormat = formatter.GetDateFormat()
+	}
+
+	handler.Lock()
+	info.Override = handler.overrides[target]
+	handler.Unlock()
+
+	return info
+}
+
+func (handler *Handler) findLogger(name string) (*golog.Logger, bool) {
+	for _, logger := range handler.registry.Loggers() {
+		if logger.GetName() == name {
+			return logger, true
+		}
+	}
+
+	return nil, false
+}
+
+func NewHandler() *Handler {
+	return NewRegistryHandler(golog.DefaultRegistry)
+}
+
+func NewRegistryHandler(registry *golog.Registry) *Handler {
+	return &Handler{
+		registry:  registry,
+		overrides: make(map[interface{}]*Override),
+	}
+}
+
+func handlerType(handler golog.Handler) string {
+	name := fmt.Sprintf("%T", handler)
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func decodeChange(writer http.ResponseWriter, request *http.Request) (change, time.Duration, bool) {
+	var body change
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		http.Error(writer, fmt.Sprintf("Can't parse request: %v", err), http.StatusBadRequest)
+		return body, 0, false
+	}
+
+	if len(body.TTL) <= 0 {
+		body.TTL = request.URL.Query().Get("ttl")
+	}
+
+	var ttl time.Duration
+	if len(body.TTL) > 0 {
+		var err error
+		ttl, err = time.ParseDuration(body.TTL)
+		if err != nil {
+			http.Error(writer, fmt.Sprintf("Invalid ttl [%s]: %v", body.TTL, err), http.StatusBadRequest)
+			return body, 0, false
+		}
+	}
+
+	return body, ttl, true
+}
+
+func methodNotAllowed(writer http.ResponseWriter, allow string) {
+	writer.Header().Set("Allow", allow)
+	http.Error(writer, "Method not allowed", http.StatusMethodNotAllowed)
+}
+
+func respond(writer http.ResponseWriter, value interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(value)
+}
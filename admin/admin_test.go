@@ -0,0 +1,160 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bambocher/golog"
+)
+
+func serve(handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+	return response
+}
+
+func waitForLevel(logger *golog.Logger, level int) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if logger.GetLevel() == level {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return false
+}
+
+func TestListLoggers(t *testing.T) {
+	registry := golog.NewRegistry()
+	registry.GetLogger("app").SetHandlers()
+	handler := NewRegistryHandler(registry)
+
+	response := serve(handler, "GET", "/", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Unexpected status %d", response.Code)
+	}
+
+	var loggers []LoggerInfo
+	if err := json.Unmarshal(response.Body.Bytes(), &loggers); err != nil {
+		t.Fatal(err)
+	}
+	if len(loggers) != 2 || loggers[0].Name != "app" || loggers[1].Name != "root" {
+		t.Fatalf("Unexpected loggers: %+v", loggers)
+	}
+	if !strings.Contains(response.Body.String(), `"handlers":[]`) {
+		t.Errorf("Expected an empty handler list, got %s", response.Body.String())
+	}
+	if loggers[1].Handlers[0].Type != "StreamHandler" {
+		t.Errorf("Unexpected handler type %s", loggers[1].Handlers[0].Type)
+	}
+}
+
+func TestSetLoggerLevel(t *testing.T) {
+	registry := golog.NewRegistry()
+	handler := NewRegistryHandler(registry)
+
+	response := serve(handler, "PUT", "/root", `{"level":"error"}`)
+	if response.Code != http.StatusOK || registry.GetRoot().GetLevel() != golog.ERROR {
+		t.Errorf("Unexpected response %d %s", response.Code, response.Body.String())
+	}
+
+	if response := serve(handler, "PUT", "/root", `{"level":"loud"}`); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected a bad request, got %d", response.Code)
+	}
+	if response := serve(handler, "PUT", "/missing", `{"level":"info"}`); response.Code != http.StatusNotFound {
+		t.Errorf("Expected not found, got %d", response.Code)
+	}
+	if response := serve(handler, "DELETE", "/root", ""); response.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected method not allowed, got %d", response.Code)
+	}
+}
+
+func TestLoggerOverrideReverts(t *testing.T) {
+	registry := golog.NewRegistry()
+	logger := registry.GetRoot()
+	handler := NewRegistryHandler(registry)
+
+	response := serve(handler, "PUT", "/root", `{"level":"error","ttl":"20ms"}`)
+	if !strings.Contains(response.Body.String(), `"override"`) {
+		t.Errorf("Expected an override, got %s", response.Body.String())
+	}
+
+	if !waitForLevel(logger, golog.DEBUG) {
+		t.Errorf("Expected the level to revert, got %d", logger.GetLevel())
+	}
+	if response := serve(handler, "GET", "/root", ""); strings.Contains(response.Body.String(), `"override"`) {
+		t.Errorf("Expected no override, got %s", response.Body.String())
+	}
+}
+
+func TestReplacedOverrideKeepsOriginal(t *testing.T) {
+	registry := golog.NewRegistry()
+	logger := registry.GetRoot()
+	handler := NewRegistryHandler(registry)
+
+	serve(handler, "PUT", "/root", `{"level":"error","ttl":"10ms"}`)
+	serve(handler, "PUT", "/root", `{"level":"warning","ttl":"50ms"}`)
+
+	time.Sleep(25 * time.Millisecond)
+	if logger.GetLevel() != golog.WARNING {
+		t.Errorf("Expected the replaced timer not to revert, got %d", logger.GetLevel())
+	}
+	if !waitForLevel(logger, golog.DEBUG) {
+		t.Errorf("Expected the original level, got %d", logger.GetLevel())
+	}
+}
+
+func TestHandlerOverrideFollowsHandler(t *testing.T) {
+	registry := golog.NewRegistry()
+	logger := registry.GetRoot()
+	stdout, stderr := registry.GetStdoutHandler(), registry.GetStderrHandler()
+	handler := NewRegistryHandler(registry)
+
+	response := serve(handler, "PATCH", "/root/handlers/1", `{"min":"critical","ttl":"1h"}`)
+	if response.Code != http.StatusOK || stderr.GetLevel().Min != golog.CRITICAL {
+		t.Fatalf("Unexpected response %d %s", response.Code, response.Body.String())
+	}
+
+	logger.SetHandlers(stderr, stdout)
+
+	var info LoggerInfo
+	json.Unmarshal(serve(handler, "GET", "/root", "").Body.Bytes(), &info)
+	if info.Handlers[0].Override == nil || info.Handlers[1].Override != nil {
+		t.Errorf("Expected the override to follow the handler: %+v", info.Handlers)
+	}
+
+	if response := serve(handler, "PUT", "/root/handlers/0", `{"min":"error","max":"debug"}`); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected a bad request, got %d", response.Code)
+	}
+	if response := serve(handler, "GET", "/root/handlers/5", ""); response.Code != http.StatusNotFound {
+		t.Errorf("Expected not found, got %d", response.Code)
+	}
+}
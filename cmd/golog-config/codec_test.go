@@ -0,0 +1,121 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const sampleConfig = `{
+    "formatters": {
+        "default": {"format": "[{time}] {level}: {message}", "dateFormat": "15:04:05", "multiline": "indent"}
+    },
+    "handlers": {
+        "file": {
+            "type": "FileHandler",
+            "level": {"min": "info", "max": "critical"},
+            "formatter": "default",
+            "properties": {"filename": "/var/log/app.log", "bufferSize": 4096, "flushInterval": "1s"},
+            "filters": [
+                {"type": "rateLimit", "properties": {"rate": 2.5, "burst": 10, "byLogger": true}},
+                {"type": "dedup", "properties": {"window": "5s", "compareCaller": false}},
+                {"type": "redaction", "properties": {"builtins": ["email", "creditCard"], "fields": ["password"]}}
+            ]
+        }
+    },
+    "loggers": {
+        "root": {"level": "debug", "handlers": ["file"]},
+        "app.db": {"level": "warning", "maxLevel": "error", "handlers": []}
+    }
+}`
+
+func canonicalJSON(t *testing.T, object map[string]interface{}) string {
+	data, err := json.Marshal(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(sampleConfig), &object); err != nil {
+		t.Fatal(err)
+	}
+	expected := canonicalJSON(t, object)
+
+	yaml, err := decodeYAML(encodeYAML(object))
+	if err != nil {
+		t.Fatalf("YAML: %v", err)
+	}
+	if actual := canonicalJSON(t, yaml); actual != expected {
+		t.Errorf("YAML round trip changed the config:\n%s\n%s", expected, actual)
+	}
+
+	data, err := encodeTOML(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toml, err := decodeTOML(data)
+	if err != nil {
+		t.Fatalf("TOML: %v\n%s", err, data)
+	}
+	if actual := canonicalJSON(t, toml); actual != expected {
+		t.Errorf("TOML round trip changed the config:\n%s\n%s", expected, actual)
+	}
+}
+
+func TestDecodeTOMLArrayOfTablesWithSubTables(t *testing.T) {
+	data := `
+[[handlers.h.filters]]
+type = "dedup"
+[handlers.h.filters.properties]
+window = "5s"
+
+[[handlers.h.filters]]
+type = "rateLimit"
+[handlers.h.filters.properties]
+rate = 1
+`
+
+	object, err := decodeTOML([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := canonicalJSON(t, object)
+	expected := `{"handlers":{"h":{"filters":[{"properties":{"window":"5s"},"type":"dedup"},{"properties":{"rate":1},"type":"rateLimit"}]}}}`
+	if actual != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestDecodeTOMLDuplicateTable(t *testing.T) {
+	_, err := decodeTOML([]byte("[a]\nx = 1\n[a]\ny = 2\n"))
+	if err == nil || !strings.Contains(err.Error(), "duplicate table") {
+		t.Errorf("Expected a duplicate table error, got %v", err)
+	}
+}
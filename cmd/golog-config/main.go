@@ -0,0 +1,317 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/bambocher/golog"
+)
+
+const usage = `Usage: golog-config <command> [flags] file...
+
+Commands:
+  validate  check config files and print every problem with its location
+  resolve   print the effective config after includes, extends and variables
+  convert   convert a config file between json, yaml and toml
+  render    format a sample record with each configured formatter
+`
+
+type codec struct {
+	decode func(data []byte) (map[string]interface{}, error)
+	encode func(object map[string]interface{}) ([]byte, error)
+}
+
+var codecs = map[string]codec{
+	"json": {
+		decode: func(data []byte) (map[string]interface{}, error) {
+			object := map[string]interface{}{}
+			err := json.Unmarshal(data, &object)
+			return object, err
+		},
+		encode: func(object map[string]interface{}) ([]byte, error) {
+			data, err := json.MarshalIndent(object, "", "    ")
+			return append(data, '\n'), err
+		},
+	},
+	"yaml": {
+		decode: decodeYAML,
+		encode: func(object map[string]interface{}) ([]byte, error) {
+			return encodeYAML(object), nil
+		},
+	},
+	"toml": {
+		decode: decodeTOML,
+		encode: encodeTOML,
+	},
+}
+
+func main() {
+	log.RegisterConfigFormat("yaml", decodeYAML)
+	log.RegisterConfigFormat("yml", decodeYAML)
+	log.RegisterConfigFormat("toml", decodeTOML)
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	commands := map[string]func(args []string) int{
+		"validate": validate,
+		"resolve":  resolve,
+		"convert":  convert,
+		"render":   render,
+	}
+
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command [%s]\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	os.Exit(command(os.Args[2:]))
+}
+
+func newFlagSet(name string, arguments string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: golog-config %s [flags] %s\n", name, arguments)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+func validate(args []string) int {
+	flags := newFlagSet("validate", "file...")
+	strict := flags.Bool("strict", false, "treat warnings as errors")
+	flags.Parse(args)
+
+	if flags.NArg() <= 0 {
+		flags.Usage()
+		return 2
+	}
+
+	status := 0
+	for _, filename := range flags.Args() {
+		raw, err := log.ResolveConfig(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", filename, err)
+			status = 1
+			continue
+		}
+
+		config, err := log.DecodeConfig(raw)
+		errs, ok := err.(log.ConfigErrors)
+		if err != nil && !ok {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", filename, err)
+			status = 1
+			continue
+		}
+		if err == nil {
+			errs = log.CheckConfig(config)
+		}
+
+		if len(errs) <= 0 {
+			fmt.Printf("%s: ok\n", filename)
+			continue
+		}
+
+		for _, problem := range errs {
+			kind := "error"
+			if problem.Warning {
+				kind = "warning"
+			}
+			fmt.Fprintf(os.Stderr, "%s: %s: %s: %s\n", filename, problem.Path, kind, problem.Message)
+		}
+
+		if errs.HasErrors() || *strict {
+			status = 1
+		}
+	}
+
+	return status
+}
+
+func resolve(args []string) int {
+	flags := newFlagSet("resolve", "file")
+	format := flags.String("format", "json", "output format: json, yaml or toml")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	object, err := log.ResolveConfig(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return write(os.Stdout, object, *format)
+}
+
+func convert(args []string) int {
+	flags := newFlagSet("convert", "file")
+	from := flags.String("from", "", "input format: json, yaml or toml (default from the file extension)")
+	to := flags.String("to", "", "output format: json, yaml or toml")
+	output := flags.String("o", "", "output file (default stdout)")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || len(*to) <= 0 {
+		flags.Usage()
+		return 2
+	}
+
+	filename := flags.Arg(0)
+	if len(*from) <= 0 {
+		*from = formatOf(filename)
+	}
+
+	input, ok := codecs[*from]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown input format [%s]\n", *from)
+		return 2
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	object, err := input.decode(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", filename, err)
+		return 1
+	}
+
+	writer := os.Stdout
+	if len(*output) > 0 {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	return write(writer, object, *to)
+}
+
+func render(args []string) int {
+	flags := newFlagSet("render", "file")
+	message := flags.String("message", "Sample message\nwith a second line", "message of the sample record")
+	level := flags.String("level", "info", "level of the sample record")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	levelNumber, err := log.ParseLevel(*level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	config, err := log.ReadConfig(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	record := log.MakeRecord(log.RecordInfo{
+		Logger:   log.NewRegistry().GetLogger("app.sample"),
+		Level:    levelNumber,
+		Time:     time.Date(2014, time.March, 14, 15, 9, 26, 535000000, time.UTC),
+		Path:     "/src/app/main.go",
+		Line:     42,
+		Function: "main.main",
+		Message:  *message,
+		Fields:   log.Fields{"user": "alice", "attempt": 3},
+	})
+
+	status := 0
+	for _, name := range sortedConfigKeys(config.Formatters) {
+		formatter, err := log.NewConfigFormatter(config.Formatters[name])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "formatters.%s: %v\n", name, err)
+			status = 1
+			continue
+		}
+
+		fmt.Printf("%s:\n%s\n", name, formatter.Format(record))
+	}
+
+	return status
+}
+
+func write(writer *os.File, object map[string]interface{}, format string) int {
+	output, ok := codecs[format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown output format [%s]\n", format)
+		return 2
+	}
+
+	data, err := output.encode(object)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func formatOf(filename string) string {
+	format := strings.TrimPrefix(filepath.Ext(filename), ".")
+	if format == "yml" {
+		return "yaml"
+	}
+
+	return format
+}
+
+func sortedConfigKeys(formatters map[string]log.ConfigFormatter) []string {
+	object := make(map[string]interface{}, len(formatters))
+	for key := range formatters {
+		object[key] = nil
+	}
+
+	return sortedKeys(object)
+}
@@ -0,0 +1,480 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var tomlBare = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func encodeTOML(object map[string]interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := writeTOMLTable(&buffer, object, nil, false); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimLeft(buffer.Bytes(), "\n"), nil
+}
+
+func writeTOMLTable(buffer *bytes.Buffer, object map[string]interface{}, path []string, array bool) error {
+	scalars := len(object) == 0
+	for _, value := range object {
+		switch typed := value.(type) {
+		case map[string]interface{}:
+		case []interface{}:
+			scalars = scalars || len(typed) == 0 || !tomlTables(typed)
+		default:
+			scalars = scalars || value != nil
+		}
+	}
+
+	if len(path) > 0 && (scalars || array) {
+		header := tomlPath(path)
+		if array {
+			buffer.WriteString("\n[[" + header + "]]\n")
+		} else {
+			buffer.WriteString("\n[" + header + "]\n")
+		}
+	}
+
+	var tables, arrays []string
+	for _, key := range sortedKeys(object) {
+		switch typed := object[key].(type) {
+		case map[string]interface{}:
+			tables = append(tables, key)
+			continue
+		case []interface{}:
+			if len(typed) > 0 && tomlTables(typed) {
+				arrays = append(arrays, key)
+				continue
+			}
+		case nil:
+			continue
+		}
+
+		value, err := tomlValue(object[key])
+		if err != nil {
+			return errors.New(fmt.Sprintf("%s: %v", tomlPath(append(path, key)), err))
+		}
+		buffer.WriteString(tomlKey(key) + " = " + value + "\n")
+	}
+
+	for _, key := range tables {
+		if err := writeTOMLTable(buffer, object[key].(map[string]interface{}), append(path[:len(path):len(path)], key), false); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range arrays {
+		for _, item := range object[key].([]interface{}) {
+			if err := writeTOMLTable(buffer, item.(map[string]interface{}), append(path[:len(path):len(path)], key), true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func tomlTables(items []interface{}) bool {
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func tomlValue(value interface{}) (string, error) {
+	switch typed := value.(type) {
+	case string:
+		return strconv.Quote(typed), nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), nil
+	case []interface{}:
+		items := make([]string, len(typed))
+		for x, item := range typed {
+			text, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[x] = text
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]interface{}:
+		items := make([]string, 0, len(typed))
+		for _, key := range sortedKeys(typed) {
+			text, err := tomlValue(typed[key])
+			if err != nil {
+				return "", err
+			}
+			items = append(items, tomlKey(key)+" = "+text)
+		}
+		return "{" + strings.Join(items, ", ") + "}", nil
+	}
+
+	return "", errors.New(fmt.Sprintf("unsupported value %v", value))
+}
+
+func tomlKey(key string) string {
+	if tomlBare.MatchString(key) {
+		return key
+	}
+
+	return strconv.Quote(key)
+}
+
+func tomlPath(path []string) string {
+	keys := make([]string, len(path))
+	for x, key := range path {
+		keys[x] = tomlKey(key)
+	}
+
+	return strings.Join(keys, ".")
+}
+
+type tomlParser struct {
+	text   string
+	pos    int
+	line   int
+	root   map[string]interface{}
+	table  map[string]interface{}
+	closed map[string]bool
+}
+
+func decodeTOML(data []byte) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	parser := &tomlParser{text: string(data), line: 1, root: root, table: root, closed: map[string]bool{}}
+
+	if err := parser.parse(); err != nil {
+		return nil, errors.New(fmt.Sprintf("line %d: %v", parser.line, err))
+	}
+
+	return root, nil
+}
+
+func (parser *tomlParser) parse() error {
+	for {
+		parser.skipSpace(true)
+		if parser.pos >= len(parser.text) {
+			return nil
+		}
+
+		if parser.peek() == '[' {
+			if err := parser.parseHeader(); err != nil {
+				return err
+			}
+		} else if err := parser.parseKeyValue(parser.table); err != nil {
+			return err
+		}
+
+		parser.skipSpace(false)
+		if parser.pos < len(parser.text) && parser.peek() != '\n' {
+			return errors.New(fmt.Sprintf("unexpected %q", parser.peek()))
+		}
+	}
+}
+
+func (parser *tomlParser) parseHeader() error {
+	array := strings.HasPrefix(parser.text[parser.pos:], "[[")
+	if array {
+		parser.pos += 2
+	} else {
+		parser.pos++
+	}
+
+	path, err := parser.parseKeyPath()
+	if err != nil {
+		return err
+	}
+
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	parser.skipSpace(false)
+	if !strings.HasPrefix(parser.text[parser.pos:], closing) {
+		return errors.New("unterminated table header")
+	}
+	parser.pos += len(closing)
+
+	table := parser.root
+	for x, key := range path {
+		last := x == len(path)-1
+		switch existing := table[key].(type) {
+		case nil:
+			if last && array {
+				item := map[string]interface{}{}
+				table[key] = []interface{}{item}
+				table = item
+				continue
+			}
+			next := map[string]interface{}{}
+			table[key] = next
+			table = next
+		case map[string]interface{}:
+			if last && array {
+				return errors.New(fmt.Sprintf("key [%s] is already a table", key))
+			}
+			table = existing
+		case []interface{}:
+			if last && array {
+				item := map[string]interface{}{}
+				table[key] = append(existing, item)
+				table = item
+				continue
+			}
+			item, ok := existing[len(existing)-1].(map[string]interface{})
+			if !ok {
+				return errors.New(fmt.Sprintf("key [%s] is not a table", key))
+			}
+			table = item
+		default:
+			return errors.New(fmt.Sprintf("key [%s] is not a table", key))
+		}
+	}
+
+	name := strings.Join(path, "\x00")
+	if array {
+		for closed := range parser.closed {
+			if strings.HasPrefix(closed, name+"\x00") {
+				delete(parser.closed, closed)
+			}
+		}
+	} else {
+		if parser.closed[name] {
+			return errors.New(fmt.Sprintf("duplicate table [%s]", tomlPath(path)))
+		}
+		parser.closed[name] = true
+	}
+	parser.table = table
+
+	return nil
+}
+
+func (parser *tomlParser) parseKeyValue(table map[string]interface{}) error {
+	path, err := parser.parseKeyPath()
+	if err != nil {
+		return err
+	}
+
+	parser.skipSpace(false)
+	if parser.pos >= len(parser.text) || parser.peek() != '=' {
+		return errors.New("expected '='")
+	}
+	parser.pos++
+	parser.skipSpace(false)
+
+	value, err := parser.parseValue()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range path[:len(path)-1] {
+		next, ok := table[key].(map[string]interface{})
+		if !ok {
+			if _, exists := table[key]; exists {
+				return errors.New(fmt.Sprintf("key [%s] is not a table", key))
+			}
+			next = map[string]interface{}{}
+			table[key] = next
+		}
+		table = next
+	}
+
+	key := path[len(path)-1]
+	if _, exists := table[key]; exists {
+		return errors.New(fmt.Sprintf("duplicate key [%s]", key))
+	}
+	table[key] = value
+
+	return nil
+}
+
+func (parser *tomlParser) parseKeyPath() ([]string, error) {
+	var path []string
+	for {
+		parser.skipSpace(false)
+		if parser.pos >= len(parser.text) {
+			return nil, errors.New("expected key")
+		}
+
+		var key string
+		switch parser.peek() {
+		case '"', '\'':
+			value, err := parser.parseString()
+			if err != nil {
+				return nil, err
+			}
+			key = value
+		default:
+			start := parser.pos
+			for parser.pos < len(parser.text) && tomlBare.MatchString(parser.text[parser.pos:parser.pos+1]) {
+				parser.pos++
+			}
+			if start == parser.pos {
+				return nil, errors.New(fmt.Sprintf("unexpected %q", parser.peek()))
+			}
+			key = parser.text[start:parser.pos]
+		}
+		path = append(path, key)
+
+		parser.skipSpace(false)
+		if parser.pos >= len(parser.text) || parser.peek() != '.' {
+			return path, nil
+		}
+		parser.pos++
+	}
+}
+
+func (parser *tomlParser) parseValue() (interface{}, error) {
+	if parser.pos >= len(parser.text) {
+		return nil, errors.New("expected value")
+	}
+
+	switch parser.peek() {
+	case '"', '\'':
+		return parser.parseString()
+	case '[':
+		parser.pos++
+		items := []interface{}{}
+		for {
+			parser.skipSpace(true)
+			if parser.pos < len(parser.text) && parser.peek() == ']' {
+				parser.pos++
+				return items, nil
+			}
+			item, err := parser.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			parser.skipSpace(true)
+			if parser.pos < len(parser.text) && parser.peek() == ',' {
+				parser.pos++
+			} else if parser.pos >= len(parser.text) || parser.peek() != ']' {
+				return nil, errors.New("expected ',' or ']' in array")
+			}
+		}
+	case '{':
+		parser.pos++
+		table := map[string]interface{}{}
+		for {
+			parser.skipSpace(false)
+			if parser.pos < len(parser.text) && parser.peek() == '}' {
+				parser.pos++
+				return table, nil
+			}
+			if err := parser.parseKeyValue(table); err != nil {
+				return nil, err
+			}
+			parser.skipSpace(false)
+			if parser.pos < len(parser.text) && parser.peek() == ',' {
+				parser.pos++
+			} else if parser.pos >= len(parser.text) || parser.peek() != '}' {
+				return nil, errors.New("expected ',' or '}' in inline table")
+			}
+		}
+	}
+
+	start := parser.pos
+	for parser.pos < len(parser.text) && !strings.ContainsRune(" \t\r\n,]}#", rune(parser.peek())) {
+		parser.pos++
+	}
+	word := parser.text[start:parser.pos]
+
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	number, err := strconv.ParseFloat(strings.Replace(word, "_", "", -1), 64)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("unsupported value [%s]", word))
+	}
+
+	return number, nil
+}
+
+func (parser *tomlParser) parseString() (string, error) {
+	quote := parser.peek()
+	start := parser.pos
+	parser.pos++
+
+	for parser.pos < len(parser.text) {
+		switch parser.text[parser.pos] {
+		case '\n':
+			return "", errors.New("unterminated string")
+		case '\\':
+			if quote == '"' {
+				parser.pos++
+			}
+		case quote:
+			parser.pos++
+			text := parser.text[start:parser.pos]
+			if quote == '\'' {
+				return text[1 : len(text)-1], nil
+			}
+			return strconv.Unquote(text)
+		}
+		parser.pos++
+	}
+
+	return "", errors.New("unterminated string")
+}
+
+func (parser *tomlParser) skipSpace(newlines bool) {
+	for parser.pos < len(parser.text) {
+		switch parser.peek() {
+		case ' ', '\t', '\r':
+			parser.pos++
+		case '\n':
+			if !newlines {
+				return
+			}
+			parser.line++
+			parser.pos++
+		case '#':
+			for parser.pos < len(parser.text) && parser.peek() != '\n' {
+				parser.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (parser *tomlParser) peek() byte {
+	return parser.text[parser.pos]
+}
@@ -0,0 +1,459 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_./][A-Za-z0-9_./ -]*$`)
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+func encodeYAML(value interface{}) []byte {
+	var buffer bytes.Buffer
+	writeYAML(&buffer, value, 0)
+
+	return buffer.Bytes()
+}
+
+func writeYAML(buffer *bytes.Buffer, value interface{}, indent int) {
+	prefix := strings.Repeat("  ", indent)
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(typed) {
+			item := typed[key]
+			buffer.WriteString(prefix + yamlScalar(key) + ":")
+			if yamlBlock(item) {
+				buffer.WriteString("\n")
+				writeYAML(buffer, item, indent+1)
+			} else {
+				buffer.WriteString(" " + yamlInline(item) + "\n")
+			}
+		}
+	case []interface{}:
+		for _, item := range typed {
+			if object, ok := item.(map[string]interface{}); ok && len(object) > 0 {
+				var nested bytes.Buffer
+				writeYAML(&nested, object, indent+1)
+				text := nested.String()
+				buffer.WriteString(prefix + "- " + strings.TrimPrefix(text, prefix+"  "))
+			} else if yamlBlock(item) {
+				buffer.WriteString(prefix + "-\n")
+				writeYAML(buffer, item, indent+1)
+			} else {
+				buffer.WriteString(prefix + "- " + yamlInline(item) + "\n")
+			}
+		}
+	default:
+		buffer.WriteString(prefix + yamlInline(value) + "\n")
+	}
+}
+
+func yamlBlock(value interface{}) bool {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		return len(typed) > 0
+	case []interface{}:
+		return len(typed) > 0
+	}
+
+	return false
+}
+
+func yamlInline(value interface{}) string {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	case string:
+		return yamlScalar(typed)
+	case nil:
+		return "null"
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func yamlScalar(text string) string {
+	switch strings.ToLower(text) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(text)
+	}
+
+	if !yamlPlain.MatchString(text) || strings.HasSuffix(text, " ") {
+		return strconv.Quote(text)
+	}
+
+	return text
+}
+
+func decodeYAML(data []byte) (map[string]interface{}, error) {
+	var lines []yamlLine
+	for x, text := range strings.Split(string(data), "\n") {
+		text = strings.TrimRight(stripComment(text), " \t\r")
+		if len(strings.TrimSpace(text)) <= 0 || text == "---" {
+			continue
+		}
+
+		trimmed := strings.TrimLeft(text, " ")
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, errors.New(fmt.Sprintf("line %d: tabs are not allowed for indentation", x+1))
+		}
+		lines = append(lines, yamlLine{x + 1, len(text) - len(trimmed), trimmed})
+	}
+
+	if len(lines) <= 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	value, rest, err := parseYAMLBlock(lines, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) > 0 {
+		return nil, errors.New(fmt.Sprintf("line %d: unexpected indentation", rest[0].number))
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("top level must be a mapping")
+	}
+
+	return object, nil
+}
+
+func parseYAMLBlock(lines []yamlLine, indent int) (interface{}, []yamlLine, error) {
+	if strings.HasPrefix(lines[0].text, "- ") || lines[0].text == "-" {
+		return parseYAMLSequence(lines, indent)
+	}
+
+	return parseYAMLMapping(lines, indent)
+}
+
+func parseYAMLSequence(lines []yamlLine, indent int) (interface{}, []yamlLine, error) {
+	result := []interface{}{}
+
+	for len(lines) > 0 && lines[0].indent == indent {
+		line := lines[0]
+		if !strings.HasPrefix(line.text, "- ") && line.text != "-" {
+			return nil, nil, errors.New(fmt.Sprintf("line %d: expected sequence item", line.number))
+		}
+
+		text := strings.TrimSpace(strings.TrimPrefix(line.text, "-"))
+		lines = lines[1:]
+
+		if len(text) <= 0 {
+			if len(lines) <= 0 || lines[0].indent <= indent {
+				result = append(result, nil)
+				continue
+			}
+			item, rest, err := parseYAMLBlock(lines, lines[0].indent)
+			if err != nil {
+				return nil, nil, err
+			}
+			result = append(result, item)
+			lines = rest
+			continue
+		}
+
+		if _, _, ok := splitYAMLKey(text); ok {
+			nested := append([]yamlLine{{line.number, indent + 2, text}}, lines...)
+			item, rest, err := parseYAMLMapping(nested, indent+2)
+			if err != nil {
+				return nil, nil, err
+			}
+			result = append(result, item)
+			lines = rest
+			continue
+		}
+
+		item, err := parseYAMLScalar(text, line.number)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, item)
+	}
+
+	return result, lines, nil
+}
+
+func parseYAMLMapping(lines []yamlLine, indent int) (interface{}, []yamlLine, error) {
+	result := map[string]interface{}{}
+
+	for len(lines) > 0 && lines[0].indent == indent {
+		line := lines[0]
+		key, text, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, nil, errors.New(fmt.Sprintf("line %d: expected key: value", line.number))
+		}
+
+		if _, exists := result[key]; exists {
+			return nil, nil, errors.New(fmt.Sprintf("line %d: duplicate key [%s]", line.number, key))
+		}
+
+		lines = lines[1:]
+
+		if len(text) > 0 {
+			value, err := parseYAMLScalar(text, line.number)
+			if err != nil {
+				return nil, nil, err
+			}
+			result[key] = value
+			continue
+		}
+
+		if len(lines) > 0 && (lines[0].indent > indent || (lines[0].indent == indent && strings.HasPrefix(lines[0].text, "- "))) {
+			value, rest, err := parseYAMLBlock(lines, lines[0].indent)
+			if err != nil {
+				return nil, nil, err
+			}
+			result[key] = value
+			lines = rest
+			continue
+		}
+
+		result[key] = nil
+	}
+
+	if len(lines) > 0 && lines[0].indent > indent {
+		return nil, nil, errors.New(fmt.Sprintf("line %d: unexpected indentation", lines[0].number))
+	}
+
+	return result, lines, nil
+}
+
+func splitYAMLKey(text string) (string, string, bool) {
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, `'`) {
+		end := closingQuote(text)
+		if end < 0 || !strings.HasPrefix(text[end+1:], ":") {
+			return "", "", false
+		}
+		key, err := unquoteYAML(text[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(text[end+2:]), true
+	}
+
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false
+	}
+
+	x := strings.Index(text, ": ")
+	if x < 0 {
+		if strings.HasSuffix(text, ":") {
+			return strings.TrimSpace(text[:len(text)-1]), "", true
+		}
+		return "", "", false
+	}
+
+	return strings.TrimSpace(text[:x]), strings.TrimSpace(text[x+2:]), true
+}
+
+func parseYAMLScalar(text string, number int) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, `"`) || strings.HasPrefix(text, `'`):
+		value, err := unquoteYAML(text)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("line %d: %v", number, err))
+		}
+		return value, nil
+	case strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{"):
+		return parseYAMLFlow(text, number)
+	}
+
+	switch strings.ToLower(text) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off":
+		return false, nil
+	case "null", "~":
+		return nil, nil
+	}
+
+	if number, err := strconv.ParseFloat(text, 64); err == nil {
+		return number, nil
+	}
+
+	return text, nil
+}
+
+func parseYAMLFlow(text string, number int) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err == nil {
+		return value, nil
+	}
+
+	if text == "[]" {
+		return []interface{}{}, nil
+	}
+
+	if text == "{}" {
+		return map[string]interface{}{}, nil
+	}
+
+	if strings.HasPrefix(text, "{") && strings.HasSuffix(text, "}") {
+		object := map[string]interface{}{}
+		for _, part := range splitFlow(text[1 : len(text)-1]) {
+			key, value, ok := splitYAMLKey(strings.TrimSpace(part))
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("line %d: expected key: value in [%s]", number, text))
+			}
+			item, err := parseYAMLScalar(value, number)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = item
+		}
+		return object, nil
+	}
+
+	if !strings.HasPrefix(text, "[") || !strings.HasSuffix(text, "]") {
+		return nil, errors.New(fmt.Sprintf("line %d: unsupported flow value [%s]", number, text))
+	}
+
+	items := []interface{}{}
+	for _, part := range splitFlow(text[1 : len(text)-1]) {
+		item, err := parseYAMLScalar(strings.TrimSpace(part), number)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func splitFlow(text string) []string {
+	var parts []string
+	var quote byte
+	depth := 0
+	start := 0
+
+	for x := 0; x < len(text); x++ {
+		switch {
+		case quote != 0:
+			if text[x] == '\\' && quote == '"' {
+				x++
+			} else if text[x] == quote {
+				quote = 0
+			}
+		case text[x] == '"' || text[x] == '\'':
+			quote = text[x]
+		case text[x] == '[' || text[x] == '{':
+			depth++
+		case text[x] == ']' || text[x] == '}':
+			depth--
+		case text[x] == ',' && depth == 0:
+			parts = append(parts, text[start:x])
+			start = x + 1
+		}
+	}
+
+	if len(strings.TrimSpace(text[start:])) > 0 {
+		parts = append(parts, text[start:])
+	}
+
+	return parts
+}
+
+func unquoteYAML(text string) (string, error) {
+	if strings.HasPrefix(text, "'") {
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return "", errors.New(fmt.Sprintf("unterminated string %s", text))
+		}
+		return strings.Replace(text[1:len(text)-1], "''", "'", -1), nil
+	}
+
+	return strconv.Unquote(text)
+}
+
+func closingQuote(text string) int {
+	quote := text[0]
+	for x := 1; x < len(text); x++ {
+		if quote == '"' && text[x] == '\\' {
+			x++
+			continue
+		}
+		if text[x] == quote {
+			if quote == '\'' && x+1 < len(text) && text[x+1] == '\'' {
+				x++
+				continue
+			}
+			return x
+		}
+	}
+
+	return -1
+}
+
+func stripComment(text string) string {
+	var quote byte
+	for x := 0; x < len(text); x++ {
+		switch {
+		case quote != 0:
+			if text[x] == '\\' && quote == '"' {
+				x++
+			} else if text[x] == quote {
+				quote = 0
+			}
+		case text[x] == '"' || text[x] == '\'':
+			if x == 0 || strings.ContainsRune(" \t[{,:-", rune(text[x-1])) {
+				quote = text[x]
+			}
+		case text[x] == '#':
+			if x == 0 || text[x-1] == ' ' || text[x-1] == '\t' {
+				return text[:x]
+			}
+		}
+	}
+
+	return text
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
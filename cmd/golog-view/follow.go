@@ -0,0 +1,118 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const idle = "\x00"
+
+var pollInterval = 250 * time.Millisecond
+
+func followFile(filename string, lines chan<- string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+	}()
+
+	reader := bufio.NewReader(file)
+	partial := ""
+	offset := int64(0)
+	sent := false
+
+	read := func() error {
+		for {
+			text, err := reader.ReadString('\n')
+			offset += int64(len(text))
+			if err != nil {
+				partial += text
+				return err
+			}
+
+			lines <- strings.TrimSuffix(partial+text, "\n")
+			partial = ""
+			sent = true
+		}
+	}
+
+	for {
+		if err := read(); err != io.EOF {
+			return err
+		}
+
+		if sent {
+			lines <- idle
+			sent = false
+		}
+
+		time.Sleep(pollInterval)
+
+		info, err := os.Stat(filename)
+		if err != nil {
+			continue
+		}
+
+		current, err := file.Stat()
+		if err != nil {
+			return err
+		}
+
+		if !os.SameFile(info, current) {
+			if err := read(); err != io.EOF {
+				return err
+			}
+			if len(partial) > 0 {
+				lines <- partial
+				partial = ""
+			}
+
+			rotated, err := os.Open(filename)
+			if err != nil {
+				continue
+			}
+
+			file.Close()
+			file = rotated
+			reader.Reset(file)
+			offset = 0
+			continue
+		}
+
+		if info.Size() < offset {
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			reader.Reset(file)
+			partial = ""
+			offset = 0
+		}
+	}
+}
@@ -0,0 +1,401 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/bambocher/golog"
+)
+
+const defaultFormat = "[{time}][{level}][{file}:{line}] {message}"
+const defaultDateFormat = "2006-01-02 15:04:05"
+
+var colors = map[int]string{
+	log.DEBUG:    "\033[90m",
+	log.INFO:     "\033[32m",
+	log.NOTICE:   "\033[36m",
+	log.WARNING:  "\033[33m",
+	log.ERROR:    "\033[31m",
+	log.CRITICAL: "\033[1;31m",
+}
+
+type fieldMatches map[string]string
+
+func (matches fieldMatches) String() string {
+	pairs := make([]string, 0, len(matches))
+	for key, value := range matches {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+func (matches fieldMatches) Set(value string) error {
+	x := strings.Index(value, "=")
+	if x < 0 {
+		matches[value] = ""
+		return nil
+	}
+
+	matches[value[:x]] = value[x+1:]
+
+	return nil
+}
+
+type filter struct {
+	min    int
+	max    int
+	logger string
+	since  time.Time
+	until  time.Time
+	fields fieldMatches
+}
+
+func (filter *filter) match(record *log.Record) bool {
+	if record.GetLevel() < filter.min || record.GetLevel() > filter.max {
+		return false
+	}
+
+	if len(filter.logger) > 0 && !strings.HasPrefix(record.GetLoggerName(), filter.logger) {
+		return false
+	}
+
+	if !filter.since.IsZero() && record.GetTime().Before(filter.since) {
+		return false
+	}
+
+	if !filter.until.IsZero() && record.GetTime().After(filter.until) {
+		return false
+	}
+
+	for key, expected := range filter.fields {
+		value, ok := record.GetField(key)
+		if !ok || (len(expected) > 0 && fmt.Sprint(value) != expected) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type printer struct {
+	writer *bufio.Writer
+	color  bool
+}
+
+func (printer *printer) print(record *log.Record) {
+	level := fmt.Sprintf("%-8s", record.GetLevelName())
+	if printer.color {
+		level = colors[record.GetLevel()] + level + "\033[0m"
+	}
+
+	header := record.GetTime().Format("2006-01-02 15:04:05.000") + " " + level
+	if len(record.GetLoggerName()) > 0 {
+		header += " " + record.GetLoggerName()
+	}
+	if len(record.GetFile()) > 0 {
+		caller := fmt.Sprintf("%s:%d", record.GetFile(), record.GetLine())
+		if printer.color {
+			caller = "\033[90m" + caller + "\033[0m"
+		}
+		header += " " + caller
+	}
+
+	lines := strings.Split(record.GetMessage(), "\n")
+	fmt.Fprintf(printer.writer, "%s %s", header, lines[0])
+
+	if fields := record.GetFields(); len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			pair := fmt.Sprintf("%s=%v", key, fields[key])
+			if printer.color {
+				pair = "\033[34m" + key + "\033[0m=" + fmt.Sprint(fields[key])
+			}
+			fmt.Fprintf(printer.writer, " %s", pair)
+		}
+	}
+	fmt.Fprintln(printer.writer)
+
+	if stack := record.GetStack(); len(stack) > 0 {
+		lines = append(lines, strings.Split(stack, "\n")...)
+	}
+
+	for _, line := range lines[1:] {
+		fmt.Fprintf(printer.writer, "    %s\n", line)
+	}
+}
+
+type reader struct {
+	input  string
+	parser *log.Parser
+	filter *filter
+	emit   func(record *log.Record)
+}
+
+func (reader *reader) read(lines <-chan string) {
+	var pending []string
+	for line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+
+		if line == idle {
+			reader.parse(pending)
+			pending = nil
+			continue
+		}
+
+		if reader.input != "template" && strings.HasPrefix(line, "{") {
+			record := &log.Record{}
+			if err := json.Unmarshal([]byte(line), record); err == nil {
+				reader.parse(pending)
+				pending = nil
+				reader.flush(record)
+				continue
+			}
+		}
+
+		if reader.input != "json" && pending != nil && reader.parser.IsContinuation(pending[0], line) {
+			pending = append(pending, line)
+		} else if reader.input != "json" && reader.parser.IsRecordStart(line) {
+			reader.parse(pending)
+			pending = []string{line}
+		} else if pending != nil {
+			pending = append(pending, line)
+		}
+	}
+
+	reader.parse(pending)
+}
+
+func (reader *reader) parse(lines []string) {
+	if len(lines) <= 0 {
+		return
+	}
+
+	record, err := reader.parser.Parse(strings.Join(lines, "\n"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	reader.flush(record)
+}
+
+func (reader *reader) flush(record *log.Record) {
+	if reader.filter.match(record) {
+		reader.emit(record)
+	}
+}
+
+func parseTime(value string, dateFormat string) (time.Time, error) {
+	if len(value) <= 0 {
+		return time.Time{}, nil
+	}
+
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, dateFormat, "2006-01-02 15:04:05", "2006-01-02"} {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("Can't parse time [%s], expected RFC3339, [%s] or a duration", value, dateFormat)
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func main() {
+	levelNames := strings.ToLower(strings.Join(log.LevelNames(), ", "))
+
+	input := flag.String("input", "auto", "input format: auto, json or template")
+	format := flag.String("format", defaultFormat, "formatter template the logs were written with")
+	dateFormat := flag.String("date-format", defaultDateFormat, "date format the logs were written with")
+	min := flag.String("min", "debug", "lowest level to show ("+levelNames+")")
+	max := flag.String("max", "critical", "highest level to show ("+levelNames+")")
+	logger := flag.String("logger", "", "only show loggers whose name starts with this prefix")
+	since := flag.String("since", "", "only show records at or after this time (RFC3339, date format or a duration ago like 15m)")
+	until := flag.String("until", "", "only show records at or before this time")
+	multiline := flag.String("multiline", "keep", "how the logs were written with multi-line messages: keep, escape, prefix or indent")
+	indent := flag.String("indent", "\t", "indent marker of continuation lines with -multiline indent")
+	color := flag.String("color", "auto", "colorize output: auto, always or never")
+	follow := flag.Bool("f", false, "follow files as they grow and across rotation")
+	fields := fieldMatches{}
+	flag.Var(fields, "field", "only show records with field key=value, or key to require the field (repeatable)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: golog-view [flags] [file...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	filter := &filter{logger: *logger, fields: fields}
+
+	var err error
+	if filter.min, err = log.ParseLevel(*min); err != nil {
+		fail(err)
+	}
+	if filter.max, err = log.ParseLevel(*max); err != nil {
+		fail(err)
+	}
+	if filter.since, err = parseTime(*since, *dateFormat); err != nil {
+		fail(err)
+	}
+	if filter.until, err = parseTime(*until, *dateFormat); err != nil {
+		fail(err)
+	}
+
+	parser, err := log.NewParser(*format, *dateFormat)
+	if err != nil {
+		fail(err)
+	}
+
+	mode, err := log.ParseMultiline(*multiline)
+	if err != nil {
+		fail(err)
+	}
+	parser.SetMultiline(mode)
+	parser.SetIndent(*indent)
+
+	switch *input {
+	case "auto", "json", "template":
+	default:
+		fail(fmt.Errorf("Unknown input format [%s]", *input))
+	}
+
+	out := &printer{writer: bufio.NewWriter(os.Stdout)}
+	switch *color {
+	case "always":
+		out.color = true
+	case "auto":
+		out.color = isTerminal(os.Stdout)
+	case "never":
+	default:
+		fail(fmt.Errorf("Unknown color mode [%s]", *color))
+	}
+
+	files := flag.Args()
+	if len(files) <= 0 {
+		files = []string{"-"}
+	}
+
+	reader := &reader{input: *input, parser: parser, filter: filter, emit: out.print}
+
+	if !*follow {
+		for _, filename := range files {
+			lines := make(chan string, 64)
+			go readFile(filename, lines)
+			reader.read(lines)
+		}
+		out.writer.Flush()
+		return
+	}
+
+	entries := make(chan *log.Record, 64)
+	var group sync.WaitGroup
+	for _, filename := range files {
+		lines := make(chan string, 64)
+		go func(filename string) {
+			defer close(lines)
+			if err := followFile(filename, lines); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}(filename)
+
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			follower := *reader
+			follower.emit = func(record *log.Record) {
+				entries <- record
+			}
+			follower.read(lines)
+		}()
+	}
+
+	go func() {
+		group.Wait()
+		close(entries)
+	}()
+
+	for record := range entries {
+		out.print(record)
+		if len(entries) == 0 {
+			out.writer.Flush()
+		}
+	}
+	out.writer.Flush()
+}
+
+func readFile(filename string, lines chan<- string) {
+	defer close(lines)
+
+	input := os.Stdin
+	if filename != "-" {
+		file, err := os.Open(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		defer file.Close()
+		input = file
+	}
+
+	if err := scanLines(input, lines); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", filename, err)
+	}
+}
+
+func scanLines(input io.Reader, lines chan<- string) error {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines <- scanner.Text()
+	}
+
+	return scanner.Err()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
@@ -0,0 +1,110 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	log "github.com/bambocher/golog"
+)
+
+func TestScanLinesReportsErrors(t *testing.T) {
+	lines := make(chan string, 4)
+	failure := errors.New("read failed")
+
+	input := iotest.TimeoutReader(strings.NewReader("first\nsecond\n"))
+	if err := scanLines(input, lines); err == nil {
+		t.Error("expected the read error")
+	}
+
+	if err := scanLines(iotest.ErrReader(failure), lines); err != failure {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+}
+
+func TestScanLinesTooLong(t *testing.T) {
+	lines := make(chan string, 1)
+	input := strings.NewReader(strings.Repeat("x", 17*1024*1024))
+
+	if err := scanLines(input, lines); err == nil {
+		t.Error("expected an error for a line over the buffer limit")
+	}
+}
+
+func TestReaderMultiline(t *testing.T) {
+	parser, err := log.NewParser("[{level}] {message}", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	reader := &reader{
+		input:  "auto",
+		parser: parser,
+		filter: &filter{min: log.DEBUG, max: log.CRITICAL},
+		emit: func(record *log.Record) {
+			messages = append(messages, record.GetMessage())
+		},
+	}
+
+	lines := make(chan string, 8)
+	for _, line := range []string{"orphan", "[INFO] first", "continued", "[ERROR] second", `{"level":"WARNING","message":"json"}`} {
+		lines <- line
+	}
+	close(lines)
+	reader.read(lines)
+
+	expected := []string{"first\ncontinued", "second", "json"}
+	if strings.Join(messages, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %q, got %q", expected, messages)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	record := &log.Record{}
+	if err := record.UnmarshalJSON([]byte(`{"level":"WARNING","logger":"app.db","message":"slow","fields":{"user":"alice"}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filter *filter
+		match  bool
+	}{
+		{&filter{min: log.DEBUG, max: log.CRITICAL}, true},
+		{&filter{min: log.ERROR, max: log.CRITICAL}, false},
+		{&filter{min: log.DEBUG, max: log.CRITICAL, logger: "app"}, true},
+		{&filter{min: log.DEBUG, max: log.CRITICAL, logger: "web"}, false},
+		{&filter{min: log.DEBUG, max: log.CRITICAL, fields: fieldMatches{"user": "alice"}}, true},
+		{&filter{min: log.DEBUG, max: log.CRITICAL, fields: fieldMatches{"user": "bob"}}, false},
+		{&filter{min: log.DEBUG, max: log.CRITICAL, fields: fieldMatches{"request": ""}}, false},
+	}
+
+	for x, test := range tests {
+		if match := test.filter.match(record); match != test.match {
+			t.Errorf("filter %d: expected %v, got %v", x, test.match, match)
+		}
+	}
+}
@@ -23,43 +23,462 @@
 package golog
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
-	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 )
 
+type ConfigSampling struct {
+	Interval   string `json:"interval"`
+	First      int    `json:"first"`
+	Thereafter int    `json:"thereafter"`
+}
+
+type ConfigRedactionPattern struct {
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+type ConfigRedaction struct {
+	Builtins []string                 `json:"builtins,omitempty"`
+	Patterns []ConfigRedactionPattern `json:"patterns,omitempty"`
+	Fields   []string                 `json:"fields,omitempty"`
+}
+
+type ConfigFormatter struct {
+	Type       string                 `json:"type,omitempty"`
+	Format     string                 `json:"format,omitempty"`
+	DateFormat string                 `json:"dateFormat,omitempty"`
+	Multiline  string                 `json:"multiline,omitempty"`
+	Indent     string                 `json:"indent,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type ConfigFilter struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type ConfigLevel struct {
+	Min string `json:"min,omitempty"`
+	Max string `json:"max,omitempty"`
+}
+
 type ConfigHandler struct {
-	Type       string
-	Level      struct{ Min, Max string }
-	Formatter  string
-	Properties map[string]string
+	Type       string                 `json:"type"`
+	Level      ConfigLevel            `json:"level"`
+	Formatter  string                 `json:"formatter,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	Sampling   *ConfigSampling        `json:"sampling,omitempty"`
+	Redaction  *ConfigRedaction       `json:"redaction,omitempty"`
+	Filters    []ConfigFilter         `json:"filters,omitempty"`
 }
 
 type ConfigLogger struct {
-	Level    string
-	Handlers []string
+	Level      string          `json:"level,omitempty"`
+	MaxLevel   string          `json:"maxLevel,omitempty"`
+	StackLevel string          `json:"stackLevel,omitempty"`
+	Handlers   []string        `json:"handlers"`
+	Sampling   *ConfigSampling `json:"sampling,omitempty"`
 }
 
 type Config struct {
-	Formatters map[string]struct{ Format, DateFormat string }
-	Handlers   map[string]ConfigHandler
-	Loggers    map[string]ConfigLogger
+	Formatters map[string]ConfigFormatter `json:"formatters,omitempty"`
+	Handlers   map[string]ConfigHandler   `json:"handlers,omitempty"`
+	Loggers    map[string]ConfigLogger    `json:"loggers,omitempty"`
+	Redaction  *ConfigRedaction           `json:"redaction,omitempty"`
+}
+
+type ConfigError struct {
+	Path    string
+	Message string
+	Warning bool
+}
+
+func (err *ConfigError) Error() string {
+	if len(err.Path) <= 0 {
+		return err.Message
+	}
+
+	return fmt.Sprintf("%s: %s", err.Path, err.Message)
+}
+
+type ConfigErrors []*ConfigError
+
+func (errs ConfigErrors) Error() string {
+	messages := make([]string, len(errs))
+	for x, err := range errs {
+		messages[x] = err.Error()
+		if err.Warning {
+			messages[x] = "warning: " + messages[x]
+		}
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (errs ConfigErrors) HasErrors() bool {
+	for _, err := range errs {
+		if !err.Warning {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (errs *ConfigErrors) add(path string, format string, args ...interface{}) {
+	*errs = append(*errs, &ConfigError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (errs *ConfigErrors) warn(path string, format string, args ...interface{}) {
+	*errs = append(*errs, &ConfigError{Path: path, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+func (errs ConfigErrors) sort() {
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Path < errs[j].Path
+	})
 }
 
 func LoadConfig(filename string) error {
-	if len(filename) <= 0 {
-		return errors.New("Empty filename")
+	return DefaultRegistry.LoadConfig(filename)
+}
+
+func (registry *Registry) LoadConfig(filename string) error {
+	config, err := registry.readConfig(filename, "")
+	if err != nil {
+		return err
 	}
 
-	ext := path.Ext(filename)
-	ext = ext[1:]
+	return registry.ApplyConfig(config)
+}
+
+func DecodeConfig(raw map[string]interface{}) (*Config, error) {
+	return DefaultRegistry.DecodeConfig(raw)
+}
 
-	switch ext {
-	case "json":
-		return LoadJSONConfig(filename)
-	default:
-		return errors.New(fmt.Sprintf("Unknown config file type %v, only JSON are supported types", ext))
+func (registry *Registry) DecodeConfig(raw map[string]interface{}) (*Config, error) {
+	var errs ConfigErrors
+	checkConfigKeys(raw, reflect.TypeOf(Config{}), "", &errs)
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		if len(errs) > 0 {
+			errs.sort()
+			return nil, errs
+		}
+		return nil, err
+	}
+
+	errs = append(errs, registry.validateConfig(config)...)
+	if errs.HasErrors() {
+		errs.sort()
+		return nil, errs
+	}
+
+	return config, nil
+}
+
+func ValidateConfig(config *Config) error {
+	return DefaultRegistry.ValidateConfig(config)
+}
+
+func (registry *Registry) ValidateConfig(config *Config) error {
+	if errs := registry.validateConfig(config); errs.HasErrors() {
+		return errs
 	}
 
 	return nil
 }
+
+func CheckConfig(config *Config) ConfigErrors {
+	return DefaultRegistry.CheckConfig(config)
+}
+
+func (registry *Registry) CheckConfig(config *Config) ConfigErrors {
+	return registry.validateConfig(config)
+}
+
+func (registry *Registry) validateConfig(config *Config) ConfigErrors {
+	var errs ConfigErrors
+
+	usedFormatters := make(map[string]bool)
+	usedHandlers := make(map[string]bool)
+
+	for key, value := range config.Formatters {
+		info := getFormatterType(value.Type)
+		if info == nil {
+			errs.add(configPath("formatters", key, "type"), "Unknown formatter type [%s]", value.Type)
+			continue
+		}
+
+		if info.template && len(value.Format) <= 0 {
+			errs.add(configPath("formatters", key, "format"), "Empty format")
+		}
+
+		if len(value.Multiline) > 0 {
+			if _, err := ParseMultiline(value.Multiline); err != nil {
+				errs.add(configPath("formatters", key, "multiline"), "%v", err)
+			}
+		}
+
+		if info.validate != nil {
+			info.validate(value.Properties, configPath("formatters", key, "properties"), &errs)
+		}
+	}
+
+	for key, value := range config.Handlers {
+		registry.validateConfigHandler(config, key, value, usedFormatters, &errs)
+	}
+
+	for key, value := range config.Loggers {
+		min := validateConfigLevel(configPath("loggers", key, "level"), value.Level, &errs)
+		max := CRITICAL
+		if len(value.MaxLevel) > 0 {
+			max = validateConfigLevel(configPath("loggers", key, "maxLevel"), value.MaxLevel, &errs)
+		}
+		if min > max {
+			errs.add(configPath("loggers", key, "maxLevel"), "Level min [%s] is above max [%s]", value.Level, value.MaxLevel)
+		}
+		validateConfigLevel(configPath("loggers", key, "stackLevel"), value.StackLevel, &errs)
+
+		for x, name := range value.Handlers {
+			if _, ok := config.Handlers[name]; !ok {
+				errs.add(fmt.Sprintf("%s[%d]", configPath("loggers", key, "handlers"), x), "Not found handler [%s]", name)
+			}
+			usedHandlers[name] = true
+		}
+
+		if value.Sampling != nil {
+			validateConfigSampling(configPath("loggers", key, "sampling"), value.Sampling, &errs)
+		}
+	}
+
+	if config.Redaction != nil {
+		validateConfigRedaction("redaction", config.Redaction, &errs)
+	}
+
+	for key := range config.Formatters {
+		if !usedFormatters[key] {
+			errs.warn(configPath("formatters", key), "Unused formatter")
+		}
+	}
+
+	for key := range config.Handlers {
+		if !usedHandlers[key] {
+			errs.warn(configPath("handlers", key), "Unused handler")
+		}
+	}
+
+	errs.sort()
+
+	return errs
+}
+
+func (registry *Registry) validateConfigHandler(config *Config, key string, value ConfigHandler, usedFormatters map[string]bool, errs *ConfigErrors) {
+	info := registry.getHandlerType(value.Type)
+	if len(value.Type) <= 0 {
+		errs.add(configPath("handlers", key, "type"), "Empty handler type")
+	} else if info == nil {
+		errs.add(configPath("handlers", key, "type"), "Unknown handler type [%s]", value.Type)
+	}
+
+	min := validateConfigLevel(configPath("handlers", key, "level", "min"), value.Level.Min, errs)
+	max := CRITICAL
+	if len(value.Level.Max) > 0 {
+		max = validateConfigLevel(configPath("handlers", key, "level", "max"), value.Level.Max, errs)
+	}
+	if min > max {
+		errs.add(configPath("handlers", key, "level"), "Level min [%s] is above max [%s]", value.Level.Min, value.Level.Max)
+	}
+
+	if len(value.Formatter) > 0 {
+		if _, ok := config.Formatters[value.Formatter]; !ok {
+			errs.add(configPath("handlers", key, "formatter"), "Not found formatter [%s]", value.Formatter)
+		}
+		usedFormatters[value.Formatter] = true
+	} else if info != nil && info.formatter {
+		errs.add(configPath("handlers", key, "formatter"), "Not found formatter for handler type [%s]", value.Type)
+	}
+
+	if info != nil && info.validate != nil {
+		info.validate(value.Properties, configPath("handlers", key, "properties"), errs)
+	}
+
+	if value.Sampling != nil {
+		validateConfigSampling(configPath("handlers", key, "sampling"), value.Sampling, errs)
+	}
+
+	if value.Redaction != nil {
+		validateConfigRedaction(configPath("handlers", key, "redaction"), value.Redaction, errs)
+	}
+
+	for x, filter := range value.Filters {
+		path := fmt.Sprintf("%s[%d]", configPath("handlers", key, "filters"), x)
+
+		filterInfo := getFilterType(filter.Type)
+		if filterInfo == nil {
+			errs.add(configPath(path, "type"), "Unknown filter type [%s]", filter.Type)
+			continue
+		}
+
+		if filterInfo.validate != nil {
+			filterInfo.validate(filter.Properties, configPath(path, "properties"), errs)
+		}
+	}
+}
+
+func configLevel(value ConfigHandler) *Level {
+	level := &Level{DEBUG, CRITICAL}
+	if len(value.Level.Min) > 0 {
+		level.Min = LevelToInt(value.Level.Min)
+	}
+	if len(value.Level.Max) > 0 {
+		level.Max = LevelToInt(value.Level.Max)
+	}
+
+	return level
+}
+
+func validateConfigLevel(path string, level string, errs *ConfigErrors) int {
+	if len(level) <= 0 {
+		return DEBUG
+	}
+
+	number, err := ParseLevel(level)
+	if err != nil {
+		errs.add(path, "%v", err)
+	}
+
+	return number
+}
+
+func validateConfigSampling(path string, config *ConfigSampling, errs *ConfigErrors) {
+	interval, err := time.ParseDuration(config.Interval)
+	if err != nil {
+		errs.add(path+".interval", "%v", err)
+	} else if interval <= 0 {
+		errs.add(path+".interval", "Interval must be positive, got [%s]", config.Interval)
+	}
+
+	if config.First < 0 {
+		errs.add(path+".first", "Must not be negative, got [%d]", config.First)
+	}
+
+	if config.Thereafter < 0 {
+		errs.add(path+".thereafter", "Must not be negative, got [%d]", config.Thereafter)
+	}
+}
+
+func validateConfigRedaction(path string, config *ConfigRedaction, errs *ConfigErrors) {
+	for x, name := range config.Builtins {
+		if _, ok := GetBuiltinRedactor(name); !ok {
+			errs.add(fmt.Sprintf("%s.builtins[%d]", path, x), "Unknown builtin redactor [%s]", name)
+		}
+	}
+
+	for x, pattern := range config.Patterns {
+		if len(pattern.Pattern) <= 0 {
+			errs.add(fmt.Sprintf("%s.patterns[%d].pattern", path, x), "Empty pattern")
+		} else if _, err := regexp.Compile(pattern.Pattern); err != nil {
+			errs.add(fmt.Sprintf("%s.patterns[%d].pattern", path, x), "%v", err)
+		}
+	}
+}
+
+func checkConfigKeys(value interface{}, typ reflect.Type, path string, errs *ConfigErrors) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if value == nil {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			errs.add(path, "Expected object, got %s", configKind(value))
+			return
+		}
+		for key, item := range object {
+			field, ok := typ.FieldByNameFunc(func(name string) bool {
+				return strings.EqualFold(name, key)
+			})
+			if !ok {
+				errs.add(configPath(path, key), "Unknown key")
+				continue
+			}
+			checkConfigKeys(item, field.Type, configPath(path, key), errs)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			errs.add(path, "Expected object, got %s", configKind(value))
+			return
+		}
+		for key, item := range object {
+			checkConfigKeys(item, typ.Elem(), configPath(path, key), errs)
+		}
+	case reflect.Slice:
+		array, ok := value.([]interface{})
+		if !ok {
+			errs.add(path, "Expected array, got %s", configKind(value))
+			return
+		}
+		for x, item := range array {
+			checkConfigKeys(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, x), errs)
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			errs.add(path, "Expected string, got %s", configKind(value))
+		}
+	case reflect.Int:
+		if number, ok := value.(float64); !ok || number != float64(int(number)) {
+			errs.add(path, "Expected integer, got %s", configKind(value))
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			errs.add(path, "Expected boolean, got %s", configKind(value))
+		}
+	}
+}
+
+func configKind(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+func configPath(parts ...string) string {
+	var path []string
+	for _, part := range parts {
+		if len(part) > 0 {
+			path = append(path, part)
+		}
+	}
+
+	return strings.Join(path, ".")
+}
@@ -0,0 +1,318 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var configParsers = map[string]func(data []byte) (map[string]interface{}, error){
+	"json": parseJSONConfig,
+}
+
+var configVariable = regexp.MustCompile(`\$(\$?)\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+func RegisterConfigFormat(name string, parse func(data []byte) (map[string]interface{}, error)) {
+	configTypes.Lock()
+	configParsers[name] = parse
+	configTypes.Unlock()
+}
+
+func ReadConfig(filename string) (*Config, error) {
+	return DefaultRegistry.readConfig(filename, "")
+}
+
+func ResolveConfig(filename string) (map[string]interface{}, error) {
+	return resolveConfig(filename, "")
+}
+
+func (registry *Registry) readConfig(filename string, format string) (*Config, error) {
+	raw, err := resolveConfig(filename, format)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := registry.DecodeConfig(raw)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid config file [%s]:\n%v", filename, err))
+	}
+
+	return config, nil
+}
+
+func resolveConfig(filename string, format string) (map[string]interface{}, error) {
+	if len(filename) <= 0 {
+		return nil, errors.New("Empty filename")
+	}
+
+	raw, err := includeConfig(filename, format, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs ConfigErrors
+	for _, section := range []string{"formatters", "handlers"} {
+		if entries, ok := raw[section].(map[string]interface{}); ok {
+			raw[section] = extendConfigSection(section, entries, &errs)
+		}
+	}
+
+	raw = substituteConfig(raw, "", &errs).(map[string]interface{})
+
+	if len(errs) > 0 {
+		errs.sort()
+		return nil, errors.New(fmt.Sprintf("Invalid config file [%s]:\n%v", filename, errs))
+	}
+
+	return raw, nil
+}
+
+func parseConfigFile(filename string, format string) (map[string]interface{}, error) {
+	if len(format) <= 0 {
+		format = strings.TrimPrefix(filepath.Ext(filename), ".")
+	}
+
+	configTypes.RLock()
+	parse, ok := configParsers[format]
+	formats := make([]string, 0, len(configParsers))
+	for name := range configParsers {
+		formats = append(formats, strings.ToUpper(name))
+	}
+	configTypes.RUnlock()
+
+	if !ok {
+		sort.Strings(formats)
+		return nil, errors.New(fmt.Sprintf("Unknown config file type %v, only %s are supported types", format, strings.Join(formats, ", ")))
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Can't load %s config file [%s]: %v", format, filename, err))
+	}
+
+	raw, err := parse(data)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Can't parse %s config file [%s]: %v", format, filename, err))
+	}
+
+	return raw, nil
+}
+
+func includeConfig(filename string, format string, stack []string) (map[string]interface{}, error) {
+	absolute, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	for x, included := range stack {
+		if included == absolute {
+			cycle := append(append([]string(nil), stack[x:]...), absolute)
+			return nil, errors.New(fmt.Sprintf("Include cycle [%s]", strings.Join(cycle, " -> ")))
+		}
+	}
+	stack = append(stack, absolute)
+
+	raw, err := parseConfigFile(filename, format)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := raw["include"]
+	if !ok {
+		return raw, nil
+	}
+	delete(raw, "include")
+
+	var includes []string
+	switch typed := value.(type) {
+	case string:
+		includes = []string{typed}
+	case []interface{}:
+		for x, item := range typed {
+			include, ok := item.(string)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("Invalid config file [%s]:\ninclude[%d]: Expected string, got %s", filename, x, configKind(item)))
+			}
+			includes = append(includes, include)
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("Invalid config file [%s]:\ninclude: Expected array, got %s", filename, configKind(value)))
+	}
+
+	merged := make(map[string]interface{})
+	for x, include := range includes {
+		var errs ConfigErrors
+		include = substituteConfig(include, fmt.Sprintf("include[%d]", x), &errs).(string)
+		if len(errs) > 0 {
+			return nil, errors.New(fmt.Sprintf("Invalid config file [%s]:\n%v", filename, errs))
+		}
+
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(filename), include)
+		}
+
+		included, err := includeConfig(include, "", stack)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeConfig(merged, included)
+	}
+
+	return mergeConfig(merged, raw), nil
+}
+
+func mergeConfig(base, override map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(base)+len(override))
+	for key, value := range base {
+		result[key] = value
+	}
+
+	for key, value := range override {
+		if baseObject, ok := result[key].(map[string]interface{}); ok {
+			if object, ok := value.(map[string]interface{}); ok {
+				result[key] = mergeConfig(baseObject, object)
+				continue
+			}
+		}
+		result[key] = value
+	}
+
+	return result
+}
+
+func extendConfigSection(section string, entries map[string]interface{}, errs *ConfigErrors) map[string]interface{} {
+	resolved := make(map[string]interface{}, len(entries))
+
+	var resolve func(key string, stack []string) map[string]interface{}
+	resolve = func(key string, stack []string) map[string]interface{} {
+		if value, ok := resolved[key]; ok {
+			object, _ := value.(map[string]interface{})
+			return object
+		}
+
+		entry, ok := entries[key].(map[string]interface{})
+		if !ok {
+			resolved[key] = entries[key]
+			return nil
+		}
+
+		value, ok := entry["extends"]
+		if !ok {
+			resolved[key] = entry
+			return entry
+		}
+
+		path := configPath(section, key, "extends")
+		result := make(map[string]interface{}, len(entry))
+		for name, item := range entry {
+			result[name] = item
+		}
+		delete(result, "extends")
+
+		base, ok := value.(string)
+		if !ok {
+			errs.add(path, "Expected string, got %s", configKind(value))
+			resolved[key] = result
+			return result
+		}
+
+		for x, name := range stack {
+			if name == base {
+				cycle := append(append([]string(nil), stack[x:]...), base)
+				errs.add(path, "Extends cycle [%s]", strings.Join(cycle, " -> "))
+				resolved[key] = result
+				return result
+			}
+		}
+
+		if _, ok := entries[base]; !ok {
+			errs.add(path, "Not found base [%s]", base)
+			resolved[key] = result
+			return result
+		}
+
+		parent := resolve(base, append(stack, base))
+		if parent != nil {
+			result = mergeConfig(parent, result)
+		}
+		resolved[key] = result
+
+		return result
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		resolve(key, []string{key})
+	}
+
+	return resolved
+}
+
+func substituteConfig(value interface{}, path string, errs *ConfigErrors) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			result[key] = substituteConfig(item, configPath(path, key), errs)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for x, item := range typed {
+			result[x] = substituteConfig(item, fmt.Sprintf("%s[%d]", path, x), errs)
+		}
+		return result
+	case string:
+		return configVariable.ReplaceAllStringFunc(typed, func(match string) string {
+			groups := configVariable.FindStringSubmatch(match)
+			if len(groups[1]) > 0 {
+				return match[1:]
+			}
+
+			if variable, ok := os.LookupEnv(groups[2]); ok && (len(variable) > 0 || len(groups[3]) <= 0) {
+				return variable
+			}
+
+			if len(groups[3]) > 0 {
+				return groups[4]
+			}
+
+			errs.add(path, "Undefined variable [%s]", groups[2])
+			return ""
+		})
+	}
+
+	return value
+}
@@ -0,0 +1,159 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestResolveConfigIncludes(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"base.json":  `{"loggers": {"root": {"level": "debug", "handlers": ["a", "b"]}}, "handlers": {"a": {"type": "NullHandler"}}}`,
+		"extra.json": `{"loggers": {"root": {"level": "info"}}}`,
+		"main.json":  `{"include": ["base.json", "extra.json"], "loggers": {"root": {"handlers": ["a"]}}}`,
+	})
+
+	raw, err := ResolveConfig(filepath.Join(dir, "main.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := raw["loggers"].(map[string]interface{})["root"].(map[string]interface{})
+	if root["level"] != "info" {
+		t.Errorf("Expected later includes to win, got %v", root["level"])
+	}
+	if handlers := root["handlers"].([]interface{}); len(handlers) != 1 || handlers[0] != "a" {
+		t.Errorf("Expected arrays to be replaced, got %v", handlers)
+	}
+	if _, ok := raw["handlers"].(map[string]interface{})["a"]; !ok {
+		t.Error("Expected included handlers to be merged")
+	}
+	if _, ok := raw["include"]; ok {
+		t.Error("Expected the include key to be removed")
+	}
+}
+
+func TestResolveConfigIncludeCycle(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"a.json": `{"include": "b.json"}`,
+		"b.json": `{"include": "a.json"}`,
+	})
+
+	_, err := ResolveConfig(filepath.Join(dir, "a.json"))
+	if err == nil || !strings.Contains(strings.ToLower(err.Error()), "cycle") {
+		t.Errorf("Expected an include cycle error, got %v", err)
+	}
+}
+
+func TestResolveConfigExtends(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"main.json": `{
+			"handlers": {
+				"file": {"type": "FileHandler", "level": {"min": "debug"}, "properties": {"filename": "main.log", "bufferSize": 4096}},
+				"errors": {"extends": "file", "level": {"min": "error"}, "properties": {"filename": "errors.log"}},
+				"loop": {"extends": "loop"},
+				"orphan": {"extends": "missing"}
+			}
+		}`,
+	})
+
+	_, err := ResolveConfig(filepath.Join(dir, "main.json"))
+	if err == nil || !strings.Contains(err.Error(), "handlers.loop") || !strings.Contains(err.Error(), "handlers.orphan") {
+		t.Fatalf("Expected extends errors, got %v", err)
+	}
+
+	dir = writeConfigFiles(t, map[string]string{
+		"main.json": `{
+			"handlers": {
+				"file": {"type": "FileHandler", "level": {"min": "debug"}, "properties": {"filename": "main.log", "bufferSize": 4096}},
+				"errors": {"extends": "file", "level": {"min": "error"}, "properties": {"filename": "errors.log"}}
+			}
+		}`,
+	})
+
+	raw, err := ResolveConfig(filepath.Join(dir, "main.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errors := raw["handlers"].(map[string]interface{})["errors"].(map[string]interface{})
+	properties := errors["properties"].(map[string]interface{})
+	if errors["type"] != "FileHandler" || properties["filename"] != "errors.log" || properties["bufferSize"] != float64(4096) {
+		t.Errorf("Unexpected extended handler: %v", errors)
+	}
+	if _, ok := errors["extends"]; ok {
+		t.Error("Expected the extends key to be removed")
+	}
+}
+
+func TestResolveConfigVariables(t *testing.T) {
+	t.Setenv("GOLOG_TEST_DIR", "/var/log/app")
+	t.Setenv("GOLOG_TEST_EMPTY", "")
+
+	dir := writeConfigFiles(t, map[string]string{
+		"main.json": `{"handlers": {"file": {"type": "FileHandler", "properties": {
+			"filename": "${GOLOG_TEST_DIR}/main.log",
+			"backup": "${GOLOG_TEST_EMPTY:-/tmp}/old.log",
+			"literal": "$${GOLOG_TEST_DIR}"
+		}}}}`,
+	})
+
+	raw, err := ResolveConfig(filepath.Join(dir, "main.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	properties := raw["handlers"].(map[string]interface{})["file"].(map[string]interface{})["properties"].(map[string]interface{})
+	expected := map[string]string{"filename": "/var/log/app/main.log", "backup": "/tmp/old.log", "literal": "${GOLOG_TEST_DIR}"}
+	for key, value := range expected {
+		if properties[key] != value {
+			t.Errorf("Expected %s to be %q, got %q", key, value, properties[key])
+		}
+	}
+}
+
+func TestResolveConfigUnsetVariable(t *testing.T) {
+	os.Unsetenv("GOLOG_TEST_UNSET")
+	dir := writeConfigFiles(t, map[string]string{
+		"main.json": `{"loggers": {"root": {"level": "${GOLOG_TEST_UNSET}"}}}`,
+	})
+
+	_, err := ResolveConfig(filepath.Join(dir, "main.json"))
+	if err == nil || !strings.Contains(err.Error(), "loggers.root.level") {
+		t.Errorf("Expected an unset variable error, got %v", err)
+	}
+}
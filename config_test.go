@@ -0,0 +1,143 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"strings"
+	"testing"
+)
+
+func validTestConfig() *Config {
+	return &Config{
+		Formatters: map[string]ConfigFormatter{"default": {Format: "{message}"}},
+		Handlers: map[string]ConfigHandler{
+			"stdout": {Type: "StreamHandler", Formatter: "default", Properties: map[string]interface{}{"stream": "os.Stdout"}},
+		},
+		Loggers: map[string]ConfigLogger{"root": {Level: "info", Handlers: []string{"stdout"}}},
+	}
+}
+
+func configPaths(errs ConfigErrors) []string {
+	paths := make([]string, len(errs))
+	for x, err := range errs {
+		paths[x] = err.Path
+	}
+
+	return paths
+}
+
+func TestValidateConfigWarningsOnly(t *testing.T) {
+	config := validTestConfig()
+	config.Handlers["unused"] = ConfigHandler{Type: "NullHandler"}
+
+	if err := ValidateConfig(config); err != nil {
+		t.Errorf("Expected no error for warnings, got %v", err)
+	}
+
+	warnings := CheckConfig(config)
+	if len(warnings) != 1 || !warnings[0].Warning || warnings[0].Path != "handlers.unused" {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+}
+
+func TestValidateConfigErrors(t *testing.T) {
+	config := validTestConfig()
+	config.Loggers["root"] = ConfigLogger{Level: "loud", Handlers: []string{"missing"}}
+	config.Handlers["stdout"] = ConfigHandler{Type: "Unknown"}
+
+	err := ValidateConfig(config)
+	errs, ok := err.(ConfigErrors)
+	if !ok || !errs.HasErrors() {
+		t.Fatalf("Expected config errors, got %v", err)
+	}
+
+	expected := []string{"formatters.default", "handlers.stdout", "handlers.stdout.type", "loggers.root.handlers[0]", "loggers.root.level"}
+	if paths := configPaths(errs); !equalStrings(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+}
+
+func TestDecodeConfigCollectsAllErrors(t *testing.T) {
+	raw := map[string]interface{}{
+		"handlers": map[string]interface{}{
+			"stdout": map[string]interface{}{"type": "StreamHandler", "colour": "red"},
+		},
+		"loggers": map[string]interface{}{
+			"root": map[string]interface{}{"level": "loud", "handlers": []interface{}{"stdout"}},
+		},
+	}
+
+	_, err := DecodeConfig(raw)
+	errs, ok := err.(ConfigErrors)
+	if !ok {
+		t.Fatalf("Expected config errors, got %v", err)
+	}
+
+	paths := strings.Join(configPaths(errs), " ")
+	for _, path := range []string{"handlers.stdout.colour", "handlers.stdout.formatter", "loggers.root.level"} {
+		if !strings.Contains(paths, path) {
+			t.Errorf("Expected an error for %s, got %v", path, errs)
+		}
+	}
+}
+
+func TestDecodeConfigTypeMismatch(t *testing.T) {
+	raw := map[string]interface{}{
+		"loggers": map[string]interface{}{"root": map[string]interface{}{"handlers": "stdout"}},
+	}
+
+	_, err := DecodeConfig(raw)
+	if err == nil || !strings.Contains(err.Error(), "loggers.root.handlers: Expected array") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestDecodeConfigWarningsOnly(t *testing.T) {
+	raw := map[string]interface{}{
+		"handlers": map[string]interface{}{"unused": map[string]interface{}{"type": "NullHandler"}},
+	}
+
+	config, err := DecodeConfig(raw)
+	if err != nil || config.Handlers["unused"].Type != "NullHandler" {
+		t.Errorf("Expected a decoded config, got %v", err)
+	}
+}
+
+func TestRegistryDecodeConfigCustomType(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterHandlerType("Custom", func(properties map[string]interface{}, level *Level, formatter *Formatter) (Handler, error) {
+		return NewNullHandler(level, formatter), nil
+	})
+
+	raw := map[string]interface{}{
+		"handlers": map[string]interface{}{"custom": map[string]interface{}{"type": "Custom"}},
+		"loggers":  map[string]interface{}{"root": map[string]interface{}{"handlers": []interface{}{"custom"}}},
+	}
+
+	if _, err := registry.DecodeConfig(raw); err != nil {
+		t.Errorf("Expected the registry handler type to be known, got %v", err)
+	}
+	if _, err := DecodeConfig(raw); err == nil {
+		t.Error("Expected the default registry to reject the custom type")
+	}
+}
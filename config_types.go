@@ -0,0 +1,722 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type HandlerFactory func(properties map[string]interface{}, level *Level, formatter *Formatter) (Handler, error)
+
+type FormatterFactory func(properties map[string]interface{}, format, dateFormat string) (*Formatter, error)
+
+type FilterFactory func(handler Handler, properties map[string]interface{}) (Handler, error)
+
+type propertiesValidator func(properties Properties, path string, errs *ConfigErrors)
+
+type handlerTypeInfo struct {
+	factory   HandlerFactory
+	validate  propertiesValidator
+	formatter bool
+}
+
+type formatterTypeInfo struct {
+	factory  FormatterFactory
+	validate propertiesValidator
+	template bool
+}
+
+type filterTypeInfo struct {
+	factory  FilterFactory
+	validate propertiesValidator
+}
+
+var configTypes sync.RWMutex
+
+var handlerTypes = map[string]*handlerTypeInfo{
+	"StreamHandler": {
+		factory: newConfigStreamHandler,
+		validate: checkProperties(map[string]propertyCheck{
+			"stream":        checkOneOf("os.Stdout", "os.Stderr"),
+			"bufferSize":    checkInt,
+			"flushInterval": checkDuration,
+			"flushLevel":    checkLevel,
+		}, "stream"),
+		formatter: true,
+	},
+	"FileHandler": {
+		factory: newConfigFileHandler,
+		validate: checkProperties(map[string]propertyCheck{
+			"filename":      checkNotEmpty,
+			"bufferSize":    checkInt,
+			"flushInterval": checkDuration,
+			"flushLevel":    checkLevel,
+		}, "filename"),
+		formatter: true,
+	},
+	"WatchedFileHandler": {
+		factory: newConfigWatchedFileHandler,
+		validate: checkProperties(map[string]propertyCheck{
+			"filename":      checkNotEmpty,
+			"bufferSize":    checkInt,
+			"flushInterval": checkDuration,
+			"flushLevel":    checkLevel,
+		}, "filename"),
+		formatter: true,
+	},
+	"NetworkHandler": {
+		factory:   newConfigNetworkHandler,
+		validate:  validateNetworkProperties,
+		formatter: true,
+	},
+}
+
+var formatterTypes = map[string]*formatterTypeInfo{
+	"template": {
+		factory: func(properties map[string]interface{}, format, dateFormat string) (*Formatter, error) {
+			return NewFormatter(format, dateFormat), nil
+		},
+		validate: checkProperties(nil),
+		template: true,
+	},
+	"json": {
+		factory: func(properties map[string]interface{}, format, dateFormat string) (*Formatter, error) {
+			return NewJSONFormatter(), nil
+		},
+		validate: checkProperties(nil),
+	},
+}
+
+var filterTypes = map[string]*filterTypeInfo{
+	"sampling": {
+		factory: newConfigSamplingFilter,
+		validate: checkProperties(map[string]propertyCheck{
+			"interval":   checkPositiveDuration,
+			"first":      checkInt,
+			"thereafter": checkInt,
+		}, "interval"),
+	},
+	"rateLimit": {
+		factory: newConfigRateLimitFilter,
+		validate: checkProperties(map[string]propertyCheck{
+			"rate":     checkFloat,
+			"burst":    checkInt,
+			"byLogger": checkBool,
+			"byLevel":  checkBool,
+			"interval": checkPositiveDuration,
+		}, "rate"),
+	},
+	"dedup": {
+		factory: newConfigDedupFilter,
+		validate: checkProperties(map[string]propertyCheck{
+			"window":        checkPositiveDuration,
+			"compareCaller": checkBool,
+		}, "window"),
+	},
+	"redaction": {
+		factory:  newConfigRedactionFilter,
+		validate: validateRedactionProperties,
+	},
+}
+
+func RegisterHandlerType(name string, factory HandlerFactory) {
+	configTypes.Lock()
+	handlerTypes[name] = &handlerTypeInfo{factory: factory}
+	configTypes.Unlock()
+}
+
+func (registry *Registry) RegisterHandlerType(name string, factory HandlerFactory) {
+	registry.Lock()
+	registry.handlerTypes[name] = &handlerTypeInfo{factory: factory}
+	registry.Unlock()
+}
+
+func RegisterFormatterType(name string, factory FormatterFactory) {
+	configTypes.Lock()
+	formatterTypes[name] = &formatterTypeInfo{factory: factory}
+	configTypes.Unlock()
+}
+
+func RegisterFilterType(name string, factory FilterFactory) {
+	configTypes.Lock()
+	filterTypes[name] = &filterTypeInfo{factory: factory}
+	configTypes.Unlock()
+}
+
+func (registry *Registry) getHandlerType(name string) *handlerTypeInfo {
+	registry.RLock()
+	info, ok := registry.handlerTypes[name]
+	registry.RUnlock()
+	if ok {
+		return info
+	}
+
+	configTypes.RLock()
+	defer configTypes.RUnlock()
+
+	return handlerTypes[name]
+}
+
+func NewConfigFormatter(value ConfigFormatter) (*Formatter, error) {
+	info := getFormatterType(value.Type)
+	if info == nil {
+		return nil, errors.New(fmt.Sprintf("Unknown formatter type [%s]", value.Type))
+	}
+
+	formatter, err := info.factory(value.Properties, value.Format, value.DateFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(value.Multiline) > 0 {
+		multiline, err := ParseMultiline(value.Multiline)
+		if err != nil {
+			return nil, err
+		}
+		formatter.SetMultiline(multiline)
+	}
+
+	if len(value.Indent) > 0 {
+		formatter.SetIndent(value.Indent)
+	}
+
+	return formatter, nil
+}
+
+func getFormatterType(name string) *formatterTypeInfo {
+	if len(name) <= 0 {
+		name = "template"
+	}
+
+	configTypes.RLock()
+	defer configTypes.RUnlock()
+
+	return formatterTypes[name]
+}
+
+func getFilterType(name string) *filterTypeInfo {
+	configTypes.RLock()
+	defer configTypes.RUnlock()
+
+	return filterTypes[name]
+}
+
+func (registry *Registry) defaultHandlerTypes() map[string]*handlerTypeInfo {
+	shared := func(handler Handler) *handlerTypeInfo {
+		return &handlerTypeInfo{
+			factory: func(properties map[string]interface{}, level *Level, formatter *Formatter) (Handler, error) {
+				return handler, nil
+			},
+			validate: checkProperties(nil),
+		}
+	}
+
+	return map[string]*handlerTypeInfo{
+		"StdoutHandler": shared(registry.stdout),
+		"StderrHandler": shared(registry.stderr),
+		"NullHandler":   shared(registry.null),
+	}
+}
+
+type Properties map[string]interface{}
+
+func (properties Properties) Has(name string) bool {
+	_, ok := properties[name]
+	return ok
+}
+
+func (properties Properties) GetString(name string, fallback string) (string, error) {
+	value, ok := properties[name]
+	if !ok {
+		return fallback, nil
+	}
+
+	switch typed := value.(type) {
+	case string:
+		return typed, nil
+	case float64, int, bool:
+		return fmt.Sprint(typed), nil
+	}
+
+	return fallback, errors.New(fmt.Sprintf("Expected string, got %s", configKind(value)))
+}
+
+func (properties Properties) GetInt(name string, fallback int) (int, error) {
+	value, ok := properties[name]
+	if !ok {
+		return fallback, nil
+	}
+
+	switch typed := value.(type) {
+	case int:
+		return typed, nil
+	case float64:
+		if typed == float64(int(typed)) {
+			return int(typed), nil
+		}
+	case string:
+		return strconv.Atoi(typed)
+	}
+
+	return fallback, errors.New(fmt.Sprintf("Expected integer, got %s", configKind(value)))
+}
+
+func (properties Properties) GetFloat(name string, fallback float64) (float64, error) {
+	value, ok := properties[name]
+	if !ok {
+		return fallback, nil
+	}
+
+	switch typed := value.(type) {
+	case float64:
+		return typed, nil
+	case int:
+		return float64(typed), nil
+	case string:
+		return strconv.ParseFloat(typed, 64)
+	}
+
+	return fallback, errors.New(fmt.Sprintf("Expected number, got %s", configKind(value)))
+}
+
+func (properties Properties) GetBool(name string, fallback bool) (bool, error) {
+	value, ok := properties[name]
+	if !ok {
+		return fallback, nil
+	}
+
+	switch typed := value.(type) {
+	case bool:
+		return typed, nil
+	case string:
+		return strconv.ParseBool(typed)
+	}
+
+	return fallback, errors.New(fmt.Sprintf("Expected boolean, got %s", configKind(value)))
+}
+
+func (properties Properties) GetDuration(name string, fallback time.Duration) (time.Duration, error) {
+	value, ok := properties[name]
+	if !ok {
+		return fallback, nil
+	}
+
+	if typed, ok := value.(string); ok {
+		return time.ParseDuration(typed)
+	}
+
+	return fallback, errors.New(fmt.Sprintf("Expected duration string, got %s", configKind(value)))
+}
+
+func (properties Properties) Decode(target interface{}) error {
+	return decodeProperties(properties, target)
+}
+
+func decodeProperties(value interface{}, target interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+type propertyCheck func(properties Properties, name string) error
+
+func checkProperties(checks map[string]propertyCheck, required ...string) propertiesValidator {
+	return func(properties Properties, path string, errs *ConfigErrors) {
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			check, ok := checks[name]
+			if !ok {
+				errs.add(configPath(path, name), "Unknown property")
+				continue
+			}
+			if err := check(properties, name); err != nil {
+				errs.add(configPath(path, name), "Invalid property: %v", err)
+			}
+		}
+
+		for _, name := range required {
+			if !properties.Has(name) {
+				errs.add(configPath(path, name), "Not found property")
+			}
+		}
+	}
+}
+
+func checkNotEmpty(properties Properties, name string) error {
+	value, err := properties.GetString(name, "")
+	if err == nil && len(value) <= 0 {
+		err = errors.New("Empty value")
+	}
+
+	return err
+}
+
+func checkOneOf(values ...string) propertyCheck {
+	return func(properties Properties, name string) error {
+		value, err := properties.GetString(name, "")
+		if err != nil {
+			return err
+		}
+
+		for _, allowed := range values {
+			if value == allowed {
+				return nil
+			}
+		}
+
+		return errors.New(fmt.Sprintf("Unknown value [%s]", value))
+	}
+}
+
+func checkInt(properties Properties, name string) error {
+	_, err := properties.GetInt(name, 0)
+	return err
+}
+
+func checkFloat(properties Properties, name string) error {
+	_, err := properties.GetFloat(name, 0)
+	return err
+}
+
+func checkBool(properties Properties, name string) error {
+	_, err := properties.GetBool(name, false)
+	return err
+}
+
+func checkDuration(properties Properties, name string) error {
+	_, err := properties.GetDuration(name, 0)
+	return err
+}
+
+func checkLevel(properties Properties, name string) error {
+	value, err := properties.GetString(name, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = ParseLevel(value)
+	return err
+}
+
+func checkPositiveDuration(properties Properties, name string) error {
+	value, err := properties.GetDuration(name, 0)
+	if err == nil && value <= 0 {
+		err = errors.New(fmt.Sprintf("Must be positive, got [%s]", value))
+	}
+
+	return err
+}
+
+func validateRedactionProperties(properties Properties, path string, errs *ConfigErrors) {
+	checkConfigKeys(map[string]interface{}(properties), reflect.TypeOf(ConfigRedaction{}), path, errs)
+
+	redaction := &ConfigRedaction{}
+	if err := properties.Decode(redaction); err != nil {
+		errs.add(path, "%v", err)
+		return
+	}
+
+	validateConfigRedaction(path, redaction, errs)
+}
+
+func newConfigStreamHandler(properties map[string]interface{}, level *Level, formatter *Formatter) (Handler, error) {
+	stream, err := Properties(properties).GetString("stream", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var handler Handler
+	switch stream {
+	case "os.Stdout":
+		handler = NewStreamHandler(level, formatter, os.Stdout)
+	case "os.Stderr":
+		handler = NewStreamHandler(level, formatter, os.Stderr)
+	default:
+		return nil, errors.New(fmt.Sprintf("Unknown stream [%s]", stream))
+	}
+
+	if err := setConfigBuffer(handler.(*StreamHandler), properties); err != nil {
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+func newConfigFileHandler(properties map[string]interface{}, level *Level, formatter *Formatter) (Handler, error) {
+	filename, err := Properties(properties).GetString("filename", "")
+	if err != nil {
+		return nil, err
+	}
+
+	handler, err := NewFileHandler(level, formatter, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	file := handler.(*FileHandler)
+	if err := setConfigBuffer(&file.StreamHandler, properties); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+func newConfigWatchedFileHandler(properties map[string]interface{}, level *Level, formatter *Formatter) (Handler, error) {
+	handler, err := newConfigFileHandler(properties, level, formatter)
+	if err != nil {
+		return nil, err
+	}
+
+	handler.(*FileHandler).watched = true
+
+	return handler, nil
+}
+
+func setConfigBuffer(handler *StreamHandler, properties map[string]interface{}) error {
+	props := Properties(properties)
+
+	size, err := props.GetInt("bufferSize", 0)
+	if err != nil {
+		return err
+	}
+
+	interval, err := props.GetDuration("flushInterval", 0)
+	if err != nil {
+		return err
+	}
+
+	flushLevel, err := props.GetString("flushLevel", "error")
+	if err != nil {
+		return err
+	}
+
+	level, err := ParseLevel(flushLevel)
+	if err != nil {
+		return err
+	}
+
+	handler.SetBufferSize(size)
+	handler.SetFlushInterval(interval)
+	handler.SetFlushLevel(level)
+
+	return nil
+}
+
+var checkNetworkProperties = checkProperties(map[string]propertyCheck{
+	"network":      checkNotEmpty,
+	"address":      checkNotEmpty,
+	"tls":          checkBool,
+	"framing":      checkOneOf("newline", "length"),
+	"writeTimeout": checkDuration,
+	"backlog":      checkInt,
+}, "network", "address")
+
+func validateNetworkProperties(properties Properties, path string, errs *ConfigErrors) {
+	checkNetworkProperties(properties, path, errs)
+
+	network, _ := properties.GetString("network", "")
+	enabled, _ := properties.GetBool("tls", false)
+	if enabled && isDatagram(network) {
+		errs.add(path+".tls", "TLS is not supported over %s", network)
+	}
+}
+
+func newConfigNetworkHandler(properties map[string]interface{}, level *Level, formatter *Formatter) (Handler, error) {
+	props := Properties(properties)
+
+	network, err := props.GetString("network", "")
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := props.GetString("address", "")
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := props.GetBool("tls", false)
+	if err != nil {
+		return nil, err
+	}
+
+	framing, err := props.GetString("framing", "newline")
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := props.GetDuration("writeTimeout", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	backlog, err := props.GetInt("backlog", -1)
+	if err != nil {
+		return nil, err
+	}
+
+	handler, err := NewNetworkHandler(level, formatter, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled {
+		if err := handler.SetTLSConfig(&tls.Config{}); err != nil {
+			handler.Close()
+			return nil, err
+		}
+	}
+
+	if framing == "length" {
+		handler.SetFraming(LengthPrefixFraming)
+	}
+
+	if timeout > 0 {
+		handler.SetWriteTimeout(timeout)
+	}
+
+	if backlog >= 0 {
+		handler.SetBacklogSize(backlog)
+	}
+
+	return handler, nil
+}
+
+func newConfigSamplingFilter(handler Handler, properties map[string]interface{}) (Handler, error) {
+	props := Properties(properties)
+
+	interval, err := props.GetDuration("interval", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := props.GetInt("first", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	thereafter, err := props.GetInt("thereafter", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sampler, err := newConfigSampler(&ConfigSampling{interval.String(), first, thereafter})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSamplingHandler(handler, sampler), nil
+}
+
+func newConfigRateLimitFilter(handler Handler, properties map[string]interface{}) (Handler, error) {
+	props := Properties(properties)
+
+	rate, err := props.GetFloat("rate", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	burst, err := props.GetInt("burst", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	byLogger, err := props.GetBool("byLogger", false)
+	if err != nil {
+		return nil, err
+	}
+
+	byLevel, err := props.GetBool("byLevel", false)
+	if err != nil {
+		return nil, err
+	}
+
+	interval, err := props.GetDuration("interval", defaultRateLimitInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	limited := NewRateLimitedHandler(handler, rate, burst)
+	limited.SetInterval(interval)
+
+	mode := 0
+	if byLogger {
+		mode |= RateLimitByLogger
+	}
+	if byLevel {
+		mode |= RateLimitByLevel
+	}
+	limited.SetKeyMode(mode)
+
+	return limited, nil
+}
+
+func newConfigDedupFilter(handler Handler, properties map[string]interface{}) (Handler, error) {
+	props := Properties(properties)
+
+	window, err := props.GetDuration("window", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	compare, err := props.GetBool("compareCaller", false)
+	if err != nil {
+		return nil, err
+	}
+
+	dedup := NewDedupHandler(handler, window)
+	dedup.SetCompareCaller(compare)
+
+	return dedup, nil
+}
+
+func newConfigRedactionFilter(handler Handler, properties map[string]interface{}) (Handler, error) {
+	redaction := &ConfigRedaction{}
+	if err := Properties(properties).Decode(redaction); err != nil {
+		return nil, err
+	}
+
+	redactors, err := newConfigRedactors(redaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedactingHandler(handler, redactors...), nil
+}
@@ -0,0 +1,186 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type prefixHandler struct {
+	recordingHandler
+	prefix string
+}
+
+func (handler *prefixHandler) Handle(record *Record) {
+	clone := record.Clone()
+	clone.message = handler.prefix + clone.message
+	handler.recordingHandler.Handle(clone)
+}
+
+func TestProperties(t *testing.T) {
+	properties := Properties{
+		"text":     "value",
+		"number":   float64(3),
+		"fraction": 1.5,
+		"flag":     true,
+		"quoted":   "7",
+		"duration": "2s",
+	}
+
+	if value, err := properties.GetString("number", ""); value != "3" || err != nil {
+		t.Errorf("GetString: %v %v", value, err)
+	}
+	if value, err := properties.GetInt("number", 0); value != 3 || err != nil {
+		t.Errorf("GetInt: %v %v", value, err)
+	}
+	if value, err := properties.GetInt("quoted", 0); value != 7 || err != nil {
+		t.Errorf("GetInt from string: %v %v", value, err)
+	}
+	if _, err := properties.GetInt("fraction", 0); err == nil {
+		t.Error("Expected an error for a fractional integer")
+	}
+	if value, err := properties.GetFloat("fraction", 0); value != 1.5 || err != nil {
+		t.Errorf("GetFloat: %v %v", value, err)
+	}
+	if value, err := properties.GetBool("flag", false); !value || err != nil {
+		t.Errorf("GetBool: %v %v", value, err)
+	}
+	if value, err := properties.GetDuration("duration", 0); value != 2*time.Second || err != nil {
+		t.Errorf("GetDuration: %v %v", value, err)
+	}
+	if value, err := properties.GetDuration("missing", time.Minute); value != time.Minute || err != nil {
+		t.Errorf("GetDuration fallback: %v %v", value, err)
+	}
+	if _, err := properties.GetBool("number", false); err == nil {
+		t.Error("Expected an error for a number as boolean")
+	}
+}
+
+func TestRegistryHandlerType(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterHandlerType("PrefixHandler", func(properties map[string]interface{}, level *Level, formatter *Formatter) (Handler, error) {
+		var options struct {
+			Prefix struct {
+				Text string `json:"text"`
+			} `json:"prefix"`
+		}
+		if err := Properties(properties).Decode(&options); err != nil {
+			return nil, err
+		}
+
+		handler := &prefixHandler{recordingHandler: *newRecordingHandler(), prefix: options.Prefix.Text}
+		handler.SetLevel(level)
+		handler.SetFormatter(formatter)
+
+		return handler, nil
+	})
+
+	err := registry.ApplyConfig(&Config{
+		Handlers: map[string]ConfigHandler{"prefix": {
+			Type:       "PrefixHandler",
+			Level:      ConfigLevel{Min: "info"},
+			Properties: map[string]interface{}{"prefix": map[string]interface{}{"text": "> "}},
+		}},
+		Loggers: map[string]ConfigLogger{"app": {Handlers: []string{"prefix"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := registry.GetLogger("app").GetHandlers()[0].(*prefixHandler)
+	registry.GetLogger("app").Debug("hidden")
+	registry.GetLogger("app").Info("shown")
+
+	if messages := handler.messages(); !equalStrings(messages, []string{"> shown"}) {
+		t.Errorf("Unexpected messages: %v", messages)
+	}
+	if handler.GetName() != "prefix" {
+		t.Errorf("Expected the config key as name, got %s", handler.GetName())
+	}
+	if NewRegistry().getHandlerType("PrefixHandler") != nil {
+		t.Error("Expected the handler type to be registered on a single registry")
+	}
+}
+
+func TestRegisterFormatterAndFilterTypes(t *testing.T) {
+	RegisterFormatterType("testUpper", func(properties map[string]interface{}, format, dateFormat string) (*Formatter, error) {
+		return NewFormatter(strings.ToUpper(format), dateFormat), nil
+	})
+	RegisterFilterType("testPrefix", func(handler Handler, properties map[string]interface{}) (Handler, error) {
+		prefix, err := Properties(properties).GetString("prefix", "")
+		if err != nil {
+			return nil, err
+		}
+
+		filtered := &prefixHandler{recordingHandler: *newRecordingHandler(), prefix: prefix}
+		filtered.SetFormatter(handler.GetFormatter())
+
+		return filtered, nil
+	})
+
+	registry := NewRegistry()
+	err := registry.ApplyConfig(&Config{
+		Formatters: map[string]ConfigFormatter{"upper": {Type: "testUpper", Format: "{message}"}},
+		Handlers: map[string]ConfigHandler{"stdout": {
+			Type:       "StreamHandler",
+			Formatter:  "upper",
+			Properties: map[string]interface{}{"stream": "os.Stdout"},
+			Filters:    []ConfigFilter{{Type: "testPrefix", Properties: map[string]interface{}{"prefix": "> "}}},
+		}},
+		Loggers: map[string]ConfigLogger{"app": {Handlers: []string{"stdout"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler, ok := registry.GetLogger("app").GetHandlers()[0].(*prefixHandler)
+	if !ok || handler.prefix != "> " {
+		t.Fatalf("Expected the filter to wrap the handler, got %T", registry.GetLogger("app").GetHandlers()[0])
+	}
+	if format := handler.GetFormatter().GetFormat(); format != "{MESSAGE}" {
+		t.Errorf("Expected the custom formatter, got %s", format)
+	}
+}
+
+func TestBuiltinHandlerTypeValidation(t *testing.T) {
+	errs := ValidateConfig(&Config{
+		Formatters: map[string]ConfigFormatter{"default": {Format: "{message}"}},
+		Handlers: map[string]ConfigHandler{
+			"stream": {Type: "StreamHandler", Formatter: "default", Properties: map[string]interface{}{"stream": "os.Stdin", "bufferSize": "big"}},
+			"file":   {Type: "FileHandler", Formatter: "default"},
+		},
+		Loggers: map[string]ConfigLogger{"root": {Handlers: []string{"stream", "file"}}},
+	})
+
+	text := ""
+	if errs != nil {
+		text = errs.Error()
+	}
+	for _, path := range []string{"handlers.stream.properties.stream", "handlers.stream.properties.bufferSize", "handlers.file.properties.filename"} {
+		if !strings.Contains(text, path) {
+			t.Errorf("Expected an error for %s, got:\n%s", path, text)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"fmt"
+	"time"
+)
+
+type DedupHandler struct {
+	BaseHandler
+	handler       Handler
+	window        time.Duration
+	compareCaller bool
+	last          *Record
+	repeated      int
+	timer         *time.Timer
+}
+
+func (handler *DedupHandler) Handle(record *Record) {
+	handler.Lock()
+	defer handler.Unlock()
+
+	if handler.last != nil && handler.same(handler.last, record) && record.time.Sub(handler.last.time) < handler.window {
+		handler.repeated++
+		record.count(droppedResult)
+		if handler.timer == nil {
+			last := handler.last
+			handler.timer = time.AfterFunc(handler.window-time.Since(last.time), func() {
+				handler.Lock()
+				if handler.last == last {
+					handler.summarize()
+					handler.last = nil
+				}
+				handler.Unlock()
+			})
+		}
+		return
+	}
+
+	handler.summarize()
+	handler.last = record
+
+	dispatch(handler.handler, record)
+}
+
+func (handler *DedupHandler) Flush() error {
+	handler.Lock()
+	handler.summarize()
+	handler.last = nil
+	handler.Unlock()
+
+	return flushHandlers([]Handler{handler.handler})
+}
+
+func (handler *DedupHandler) Close() error {
+	err := handler.Flush()
+	if closeErr := closeHandlers([]Handler{handler.handler}); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func (handler *DedupHandler) summarize() {
+	if handler.timer != nil {
+		handler.timer.Stop()
+		handler.timer = nil
+	}
+
+	if handler.repeated > 0 {
+		dispatch(handler.handler, MakeRecord(RecordInfo{
+			Logger:  handler.last.logger,
+			Level:   handler.last.level,
+			Message: fmt.Sprintf("last message repeated %d times", handler.repeated),
+		}))
+		handler.repeated = 0
+	}
+}
+
+func (handler *DedupHandler) same(last, record *Record) bool {
+	if last.logger != record.logger || last.level != record.level || last.message != record.message {
+		return false
+	}
+
+	if handler.compareCaller {
+		return last.path == record.path && last.line == record.line
+	}
+
+	return true
+}
+
+func (handler *DedupHandler) GetHandler() Handler {
+	return handler.handler
+}
+
+func (handler *DedupHandler) SetWindow(window time.Duration) {
+	handler.Lock()
+	handler.window = window
+	handler.Unlock()
+}
+
+func (handler *DedupHandler) GetWindow() time.Duration {
+	return handler.window
+}
+
+func (handler *DedupHandler) SetCompareCaller(compare bool) {
+	handler.Lock()
+	handler.compareCaller = compare
+	handler.Unlock()
+}
+
+func (handler *DedupHandler) GetCompareCaller() bool {
+	return handler.compareCaller
+}
+
+func NewDedupHandler(handler Handler, window time.Duration) *DedupHandler {
+	dedup := &DedupHandler{
+		BaseHandler: BaseHandler{formatter: handler.GetFormatter()},
+		handler:     handler,
+		window:      window,
+	}
+	dedup.SetAtomicLevel(NewAtomicLevel(handler.GetLevel()))
+
+	return dedup
+}
@@ -0,0 +1,94 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDedupHandlerSuppressesRepeats(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewDedupHandler(target, time.Hour)
+	defer handler.Close()
+
+	for x := 0; x < 4; x++ {
+		handler.Handle(newTestRecord(INFO, "same"))
+	}
+	handler.Handle(newTestRecord(INFO, "other"))
+
+	expected := []string{"same", "last message repeated 3 times", "other"}
+	if messages := target.messages(); !equalStrings(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestDedupHandlerDistinguishesLevels(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewDedupHandler(target, time.Hour)
+	defer handler.Close()
+
+	handler.Handle(newTestRecord(INFO, "same"))
+	handler.Handle(newTestRecord(ERROR, "same"))
+
+	if messages := target.messages(); !equalStrings(messages, []string{"same", "same"}) {
+		t.Errorf("Unexpected messages: %v", messages)
+	}
+}
+
+func TestDedupHandlerCompareCaller(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewDedupHandler(target, time.Hour)
+	handler.SetCompareCaller(true)
+	defer handler.Close()
+
+	handler.Handle(MakeRecord(RecordInfo{Level: INFO, Message: "same", Path: "main.go", Line: 1}))
+	handler.Handle(MakeRecord(RecordInfo{Level: INFO, Message: "same", Path: "main.go", Line: 2}))
+	handler.Handle(MakeRecord(RecordInfo{Level: INFO, Message: "same", Path: "main.go", Line: 2}))
+
+	handler.Flush()
+	expected := []string{"same", "same", "last message repeated 1 times"}
+	if messages := target.messages(); !equalStrings(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestDedupHandlerWindowExpires(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewDedupHandler(target, 20*time.Millisecond)
+	defer handler.Close()
+
+	handler.Handle(newTestRecord(INFO, "same"))
+	handler.Handle(newTestRecord(INFO, "same"))
+
+	messages := waitForMessages(target, 2)
+	if !equalStrings(messages, []string{"same", "last message repeated 1 times"}) {
+		t.Fatalf("Unexpected messages: %v", messages)
+	}
+
+	handler.Handle(newTestRecord(INFO, "same"))
+	expected := []string{"same", "last message repeated 1 times", "same"}
+	if messages := target.messages(); !equalStrings(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
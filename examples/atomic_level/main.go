@@ -0,0 +1,47 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	log "github.com/bambocher/golog"
+	"net/http"
+	"time"
+)
+
+func main() {
+	level := log.NewAtomicLevel(&log.Level{Min: log.INFO, Max: log.CRITICAL})
+
+	log.GetLogger("root").SetAtomicLevel(level)
+	log.GetLogger("test").SetAtomicLevel(level)
+
+	// curl -X PUT -d '{"level":"debug"}' http://127.0.0.1:8080/level
+	http.Handle("/level", level)
+	go http.ListenAndServe("127.0.0.1:8080", nil)
+
+	for {
+		log.Debug("Debug message.")
+		log.Info("Informational message.")
+		log.GetLogger("test").Debug("Debug message.")
+		time.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,72 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	log "github.com/bambocher/golog"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func benchmark(name string, create func(filename string) (log.Handler, error)) {
+	dir, err := ioutil.TempDir("", "golog")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	handler, err := create(path.Join(dir, name+".log"))
+	if err != nil {
+		panic(err)
+	}
+	defer handler.(*log.FileHandler).Close()
+
+	record := log.MakeRecord(log.RecordInfo{
+		Level:   log.INFO,
+		Path:    "main.go",
+		Line:    42,
+		Message: "Informational message.",
+	})
+
+	result := testing.Benchmark(func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			handler.Handle(record)
+		}
+	})
+
+	fmt.Printf("%-12s %s\n", name, result)
+}
+
+func main() {
+	benchmark("unbuffered", func(filename string) (log.Handler, error) {
+		return log.NewFileHandler(log.AllLevels, log.DefaultFormatter, filename)
+	})
+
+	benchmark("buffered", func(filename string) (log.Handler, error) {
+		return log.NewBufferedFileHandler(log.AllLevels, log.DefaultFormatter, filename, 64*1024, time.Second)
+	})
+}
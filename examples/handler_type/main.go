@@ -0,0 +1,68 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	log "github.com/bambocher/golog"
+)
+
+type PrefixHandler struct {
+	log.BaseHandler
+	prefix string
+	times  int
+}
+
+func (handler *PrefixHandler) Handle(record *log.Record) {
+	os.Stdout.WriteString(strings.Repeat(handler.prefix, handler.times) + handler.GetFormatter().Format(record))
+}
+
+func main() {
+	log.RegisterHandlerType("PrefixHandler", func(properties map[string]interface{}, level *log.Level, formatter *log.Formatter) (log.Handler, error) {
+		prefix, err := log.Properties(properties).GetString("prefix", ">")
+		if err != nil {
+			return nil, err
+		}
+
+		times, err := log.Properties(properties).GetInt("times", 1)
+		if err != nil {
+			return nil, err
+		}
+
+		handler := &PrefixHandler{prefix: prefix, times: times}
+		handler.SetLevel(level)
+		handler.SetFormatter(formatter)
+
+		return handler, nil
+	})
+
+	err := log.LoadConfig("main.json")
+	if err != nil {
+		panic(err)
+	}
+
+	log.Info("Informational message.")
+	log.Error("Error message.")
+}
@@ -0,0 +1,46 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	log "github.com/bambocher/golog"
+	"os"
+)
+
+func main() {
+	stderr := log.NewStreamHandler(log.AllLevels, log.DefaultFormatter, os.Stderr)
+	memory := log.NewMemoryHandler(log.AllLevels, log.DefaultFormatter, stderr, 100, log.ERROR)
+
+	root := log.GetLogger("root")
+	root.SetLevel(log.DEBUG)
+	root.SetHandlers(memory)
+
+	log.Debug("Debug message.")
+	log.Info("Informational message.")
+	log.Notice("Notice message.")
+	log.Warning("Warning message.")
+	log.Error("Error message.")
+
+	log.Debug("Debug message after error.")
+	memory.Dump()
+}
@@ -0,0 +1,44 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	log "github.com/bambocher/golog"
+	"os"
+)
+
+func main() {
+	modes := []int{log.MultilineKeep, log.MultilineEscape, log.MultilinePrefix, log.MultilineIndent}
+
+	for _, mode := range modes {
+		formatter := log.NewFormatter("[{time}][{level}][{file}:{line}] {message}\n{stack}", "2006-01-02 15:04:05")
+		formatter.SetMultiline(mode)
+
+		test_log := log.GetLogger(log.MultilineToString(mode))
+		test_log.SetStackLevel(log.ERROR)
+		test_log.SetHandlers(log.NewStreamHandler(log.AllLevels, formatter, os.Stdout))
+
+		test_log.Info("Informational message\nwith a second line.")
+		test_log.Error("Error message with a stack.")
+	}
+}
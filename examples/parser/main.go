@@ -0,0 +1,51 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	log "github.com/bambocher/golog"
+	"strings"
+)
+
+const text = `[2014-11-02 15:04:05][INFO][main.go:12] Server started.
+[2014-11-02 15:04:06][ERROR][main.go:27] Request failed:
+connection reset by peer
+[2014-11-02 15:04:07][WARNING][main.go:31] Retrying.
+`
+
+func main() {
+	parser, err := log.NewParser("[{time}][{level}][{file}:{line}] {message}", "2006-01-02 15:04:05")
+	if err != nil {
+		panic(err)
+	}
+
+	err = parser.ReadRecords(strings.NewReader(text), func(record *log.Record) error {
+		fmt.Printf("%s %s %s:%d %q\n", record.GetTime().Format("15:04:05"), record.GetLevelName(),
+			record.GetFile(), record.GetLine(), record.GetMessage())
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,340 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+type configExporter struct {
+	registry   *Registry
+	config     *Config
+	formatters map[*Formatter]string
+	handlers   map[Handler]string
+}
+
+func ExportConfig() (*Config, error) {
+	return DefaultRegistry.ExportConfig()
+}
+
+func (registry *Registry) ExportConfig() (*Config, error) {
+	config, _, err := registry.ExportConfigWithWarnings()
+
+	return config, err
+}
+
+func ExportConfigWithWarnings() (*Config, ConfigErrors, error) {
+	return DefaultRegistry.ExportConfigWithWarnings()
+}
+
+func (registry *Registry) ExportConfigWithWarnings() (*Config, ConfigErrors, error) {
+	var warnings ConfigErrors
+
+	exporter := &configExporter{
+		registry: registry,
+		config: &Config{
+			Formatters: make(map[string]ConfigFormatter),
+			Handlers:   make(map[string]ConfigHandler),
+			Loggers:    make(map[string]ConfigLogger),
+		},
+		formatters: make(map[*Formatter]string),
+		handlers:   make(map[Handler]string),
+	}
+
+	if redactors := registry.GetRedactors(); len(redactors) > 0 {
+		redaction, unsupported := exportRedaction(redactors)
+		for _, redactor := range unsupported {
+			warnings.warn("redaction", "Skipped redactor: Unsupported redactor type [%T]", redactor)
+		}
+		if len(unsupported) < len(redactors) {
+			exporter.config.Redaction = redaction
+		}
+	}
+
+	for _, logger := range registry.Loggers() {
+		level := logger.GetAtomicLevel().GetLevel()
+		value := ConfigLogger{
+			Level:    strings.ToLower(LevelToString(level.Min)),
+			Handlers: []string{},
+		}
+
+		if level.Max != CRITICAL {
+			value.MaxLevel = strings.ToLower(LevelToString(level.Max))
+		}
+
+		if level := logger.GetStackLevel(); level <= CRITICAL {
+			value.StackLevel = strings.ToLower(LevelToString(level))
+		}
+
+		if sampler := logger.GetSampler(); sampler != nil {
+			value.Sampling = exportSampling(sampler)
+		}
+
+		for x, handler := range logger.GetHandlers() {
+			name, err := exporter.handler(handler)
+			if err != nil {
+				warnings.warn(fmt.Sprintf("%s[%d]", configPath("loggers", logger.GetName(), "handlers"), x), "Skipped handler: %v", err)
+				continue
+			}
+			value.Handlers = append(value.Handlers, name)
+		}
+
+		exporter.config.Loggers[logger.GetName()] = value
+	}
+
+	return exporter.config, warnings, nil
+}
+
+func (exporter *configExporter) handler(handler Handler) (string, error) {
+	if name, ok := exporter.handlers[handler]; ok {
+		return name, nil
+	}
+
+	value := ConfigHandler{}
+	inner := handler
+
+	for {
+		filter, next, err := exportFilter(inner)
+		if err != nil {
+			return "", err
+		}
+		if next == nil {
+			break
+		}
+		value.Filters = append([]ConfigFilter{filter}, value.Filters...)
+		inner = next
+	}
+
+	level := inner.GetLevel()
+	value.Level.Min = strings.ToLower(LevelToString(level.Min))
+	value.Level.Max = strings.ToLower(LevelToString(level.Max))
+
+	switch inner {
+	case exporter.registry.stdout:
+		value.Type = "StdoutHandler"
+	case exporter.registry.stderr:
+		value.Type = "StderrHandler"
+	case exporter.registry.null:
+		value.Type = "NullHandler"
+	}
+
+	if len(value.Type) > 0 {
+		if formatter := inner.GetFormatter(); formatter != nil {
+			value.Formatter = exporter.formatter(formatter)
+		}
+	} else {
+		value.Properties = make(map[string]interface{})
+
+		switch typed := inner.(type) {
+		case *StreamHandler:
+			switch typed.stream {
+			case os.Stdout:
+				value.Type = "StreamHandler"
+				value.Properties["stream"] = "os.Stdout"
+			case os.Stderr:
+				value.Type = "StreamHandler"
+				value.Properties["stream"] = "os.Stderr"
+			default:
+				value.Type = "FileHandler"
+				value.Properties["filename"] = typed.stream.Name()
+			}
+			exportBuffer(typed, value.Properties)
+		case *FileHandler:
+			value.Type = "FileHandler"
+			if typed.IsWatched() {
+				value.Type = "WatchedFileHandler"
+			}
+			value.Properties["filename"] = typed.GetFilename()
+			exportBuffer(&typed.StreamHandler, value.Properties)
+		case *NetworkHandler:
+			value.Type = "NetworkHandler"
+			value.Properties["network"] = typed.GetNetwork()
+			value.Properties["address"] = typed.GetAddress()
+			if typed.GetTLSConfig() != nil {
+				value.Properties["tls"] = true
+			}
+			if typed.GetFraming() == LengthPrefixFraming {
+				value.Properties["framing"] = "length"
+			}
+			if timeout := typed.GetWriteTimeout(); timeout > 0 {
+				value.Properties["writeTimeout"] = timeout.String()
+			}
+			if size := typed.GetBacklogSize(); size != defaultBacklogSize {
+				value.Properties["backlog"] = size
+			}
+		case *BaseHandler:
+			value = ConfigHandler{Type: "NullHandler", Filters: value.Filters}
+		default:
+			return "", errors.New(fmt.Sprintf("Unsupported handler type [%s]", handlerType(inner)))
+		}
+
+		if len(value.Properties) > 0 {
+			formatter := inner.GetFormatter()
+			if formatter == nil {
+				return "", errors.New(fmt.Sprintf("Not found formatter for handler type [%s]", value.Type))
+			}
+			value.Formatter = exporter.formatter(formatter)
+		}
+	}
+
+	base, ok := exporter.registry.knownHandlerName(inner)
+	if !ok {
+		base = fmt.Sprintf("%s%d", strings.ToLower(handlerType(inner)), len(exporter.handlers)+1)
+	}
+	name := base
+	for x := 2; exporter.config.Handlers[name].Type != ""; x++ {
+		name = fmt.Sprintf("%s_%d", base, x)
+	}
+
+	exporter.handlers[handler] = name
+	exporter.config.Handlers[name] = value
+
+	return name, nil
+}
+
+func (exporter *configExporter) formatter(formatter *Formatter) string {
+	if name, ok := exporter.formatters[formatter]; ok {
+		return name
+	}
+
+	name := fmt.Sprintf("formatter%d", len(exporter.formatters)+1)
+	if formatter == exporter.registry.formatter {
+		name = "default"
+	}
+
+	exporter.formatters[formatter] = name
+	value := ConfigFormatter{Type: formatter.kind}
+	if formatter.encode == nil {
+		value.Format = formatter.GetFormat()
+		value.DateFormat = formatter.GetDateFormat()
+		if formatter.GetMultiline() != MultilineKeep {
+			value.Multiline = MultilineToString(formatter.GetMultiline())
+		}
+		if formatter.GetIndent() != defaultIndent {
+			value.Indent = formatter.GetIndent()
+		}
+	}
+	exporter.config.Formatters[name] = value
+
+	return name
+}
+
+func exportBuffer(handler *StreamHandler, properties map[string]interface{}) {
+	if size := handler.GetBufferSize(); size > 0 {
+		properties["bufferSize"] = size
+	}
+	if interval := handler.GetFlushInterval(); interval > 0 {
+		properties["flushInterval"] = interval.String()
+	}
+	if level := handler.GetFlushLevel(); level != ERROR && level <= CRITICAL {
+		properties["flushLevel"] = strings.ToLower(LevelToString(level))
+	}
+}
+
+func exportFilter(handler Handler) (ConfigFilter, Handler, error) {
+	switch typed := handler.(type) {
+	case *SamplingHandler:
+		return ConfigFilter{"sampling", map[string]interface{}{
+			"interval":   typed.sampler.interval.String(),
+			"first":      typed.sampler.first,
+			"thereafter": typed.sampler.thereafter,
+		}}, typed.GetHandler(), nil
+	case *RateLimitedHandler:
+		rate, burst := typed.GetRate()
+		properties := map[string]interface{}{
+			"rate":     rate,
+			"burst":    burst,
+			"byLogger": typed.GetKeyMode()&RateLimitByLogger != 0,
+			"byLevel":  typed.GetKeyMode()&RateLimitByLevel != 0,
+		}
+		if interval := typed.GetInterval(); interval != defaultRateLimitInterval {
+			properties["interval"] = interval.String()
+		}
+		return ConfigFilter{"rateLimit", properties}, typed.GetHandler(), nil
+	case *DedupHandler:
+		return ConfigFilter{"dedup", map[string]interface{}{
+			"window":        typed.GetWindow().String(),
+			"compareCaller": typed.GetCompareCaller(),
+		}}, typed.GetHandler(), nil
+	case *RedactingHandler:
+		redaction, unsupported := exportRedaction(typed.GetRedactors())
+		if len(unsupported) > 0 {
+			return ConfigFilter{}, nil, errors.New(fmt.Sprintf("Unsupported redactor type [%T]", unsupported[0]))
+		}
+
+		properties := make(map[string]interface{})
+		if err := decodeProperties(redaction, &properties); err != nil {
+			return ConfigFilter{}, nil, err
+		}
+
+		return ConfigFilter{"redaction", properties}, typed.GetHandler(), nil
+	}
+
+	return ConfigFilter{}, nil, nil
+}
+
+func exportSampling(sampler *Sampler) *ConfigSampling {
+	return &ConfigSampling{
+		Interval:   sampler.interval.String(),
+		First:      sampler.first,
+		Thereafter: sampler.thereafter,
+	}
+}
+
+func exportRedaction(redactors []Redactor) (*ConfigRedaction, []Redactor) {
+	redaction := &ConfigRedaction{}
+
+	var unsupported []Redactor
+	for _, redactor := range redactors {
+		switch typed := redactor.(type) {
+		case *RegexRedactor:
+			if name := builtinRedactorName(typed); len(name) > 0 {
+				redaction.Builtins = append(redaction.Builtins, name)
+				continue
+			}
+			redaction.Patterns = append(redaction.Patterns, ConfigRedactionPattern{typed.pattern.String(), typed.replacement})
+		case *FieldRedactor:
+			names := typed.GetNames()
+			sort.Strings(names)
+			redaction.Fields = append(redaction.Fields, names...)
+		default:
+			unsupported = append(unsupported, redactor)
+		}
+	}
+
+	return redaction, unsupported
+}
+
+func builtinRedactorName(redactor *RegexRedactor) string {
+	for name, builtin := range builtinRedactors {
+		if builtin == Redactor(redactor) {
+			return name
+		}
+	}
+
+	return ""
+}
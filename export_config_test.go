@@ -0,0 +1,193 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportConfigBuiltinHandlers(t *testing.T) {
+	registry := NewRegistry()
+	registry.GetStdoutHandler().SetLevel(&Level{INFO, WARNING})
+	root := registry.GetRoot()
+	root.GetAtomicLevel().SetLevel(&Level{INFO, ERROR})
+	root.SetHandlers(registry.GetStdoutHandler(), registry.GetNullHandler())
+
+	config, err := registry.ExportConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := config.Handlers["stdout"]
+	if stdout.Type != "StdoutHandler" || stdout.Level != (ConfigLevel{"info", "warning"}) || stdout.Formatter != "default" {
+		t.Errorf("Unexpected stdout handler: %+v", stdout)
+	}
+	if config.Formatters["default"].Format != defaultFormat {
+		t.Errorf("Unexpected default formatter: %+v", config.Formatters["default"])
+	}
+	if logger := config.Loggers["root"]; logger.Level != "info" || logger.MaxLevel != "error" {
+		t.Errorf("Unexpected root logger: %+v", logger)
+	}
+}
+
+func TestExportConfigSkipsUnsupportedHandlers(t *testing.T) {
+	registry := NewRegistry()
+	memory := NewMemoryHandler(AllLevels, DefaultFormatter, nil, 10, ERROR)
+	registry.GetRoot().SetHandlers(memory, registry.GetStderrHandler())
+
+	config, warnings, err := registry.ExportConfigWithWarnings()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if handlers := config.Loggers["root"].Handlers; !equalStrings(handlers, []string{"stderr"}) {
+		t.Errorf("Unexpected handlers: %v", handlers)
+	}
+	if len(warnings) != 1 || !warnings[0].Warning || warnings[0].Path != "loggers.root.handlers[0]" {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+	if !strings.Contains(warnings[0].Message, "MemoryHandler") {
+		t.Errorf("Expected the handler type in the warning, got %s", warnings[0].Message)
+	}
+}
+
+func TestExportConfigRoundTrip(t *testing.T) {
+	source := NewRegistry()
+	formatter := NewFormatter("{level} {message}", "15:04:05")
+	file, err := NewFileHandler(&Level{INFO, CRITICAL}, formatter, filepath.Join(t.TempDir(), "main.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.(*FileHandler).Close()
+
+	limited := NewRateLimitedHandler(file, 10, 5)
+	limited.SetInterval(time.Minute)
+
+	logger := source.GetLogger("db")
+	logger.GetAtomicLevel().SetLevel(&Level{INFO, ERROR})
+	logger.SetStackLevel(ERROR)
+	logger.SetHandlers(limited, source.GetStderrHandler())
+	source.GetStderrHandler().SetLevel(&Level{WARNING, CRITICAL})
+
+	config, err := source.ExportConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := NewRegistry()
+	if err := target.ApplyConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+
+	exported, err := target.ExportConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := json.Marshal(config)
+	second, _ := json.Marshal(exported)
+	if !bytes.Equal(first, second) {
+		t.Errorf("Round trip changed the config:\n%s\n%s", first, second)
+	}
+
+	restored := target.GetLogger("db")
+	if level := restored.GetAtomicLevel().GetLevel(); *level != (Level{INFO, ERROR}) || restored.GetStackLevel() != ERROR {
+		t.Errorf("Unexpected logger levels: %v, stack %d", level, restored.GetStackLevel())
+	}
+	if level := target.GetStderrHandler().GetLevel(); *level != (Level{WARNING, CRITICAL}) {
+		t.Errorf("Unexpected stderr level: %v", level)
+	}
+}
+
+func TestApplyConfigKeepsBuiltinHandlersOnError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewRegistry()
+	stdout := registry.GetStdoutHandler()
+	formatter := stdout.GetFormatter()
+
+	err := registry.ApplyConfig(&Config{
+		Formatters: map[string]ConfigFormatter{"short": {Format: "{message}"}},
+		Handlers: map[string]ConfigHandler{
+			"stdout": {Type: "StdoutHandler", Formatter: "short", Level: ConfigLevel{Min: "error"}},
+			"zfile": {
+				Type:       "FileHandler",
+				Formatter:  "short",
+				Properties: map[string]interface{}{"filename": filepath.Join(dir, "file", "main.log")},
+			},
+		},
+		Loggers: map[string]ConfigLogger{"root": {Handlers: []string{"stdout", "zfile"}}},
+	})
+	if err == nil {
+		t.Fatal("expected an error for the file handler")
+	}
+
+	if level := stdout.GetLevel(); *level != *InfoLevels {
+		t.Errorf("expected the stdout level to be kept, got %v", level)
+	}
+	if stdout.GetFormatter() != formatter {
+		t.Error("expected the stdout formatter to be kept")
+	}
+}
+
+func TestExportConfigSkipsUnsupportedRedactors(t *testing.T) {
+	custom := RedactorFunc(func(record *Record) *Record { return record })
+
+	registry := NewRegistry()
+	registry.SetRedactors(EmailRedactor, custom, NewFieldRedactor("password"))
+	redacting := NewRedactingHandler(registry.GetNullHandler(), custom)
+	registry.GetRoot().SetHandlers(redacting, registry.GetStderrHandler())
+
+	config, warnings, err := registry.ExportConfigWithWarnings()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if redaction := config.Redaction; redaction == nil || !equalStrings(redaction.Builtins, []string{"email"}) ||
+		!equalStrings(redaction.Fields, []string{"password"}) {
+		t.Errorf("Unexpected redaction: %+v", config.Redaction)
+	}
+	if handlers := config.Loggers["root"].Handlers; !equalStrings(handlers, []string{"stderr"}) {
+		t.Errorf("Unexpected handlers: %v", handlers)
+	}
+
+	paths := configPaths(warnings)
+	if !equalStrings(paths, []string{"redaction", "loggers.root.handlers[0]"}) {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+	for _, warning := range warnings {
+		if !strings.Contains(warning.Message, "RedactorFunc") {
+			t.Errorf("Expected the redactor type in the warning, got %s", warning.Message)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import "time"
+
+type FailoverHandler struct {
+	BaseHandler
+	primary       Handler
+	secondary     Handler
+	retryInterval time.Duration
+	failed        bool
+	failedAt      time.Time
+	onError       func(handler Handler, record *Record, err error)
+}
+
+func (handler *FailoverHandler) Handle(record *Record) {
+	if !inLevel(handler.primary, record) {
+		return
+	}
+
+	handler.Lock()
+	usePrimary := !handler.failed || time.Since(handler.failedAt) >= handler.retryInterval
+	onError := handler.onError
+	handler.Unlock()
+
+	if usePrimary {
+		err := tryHandle(handler.primary, record)
+
+		handler.Lock()
+		handler.failed = err != nil
+		if err != nil {
+			handler.failedAt = time.Now()
+		}
+		handler.Unlock()
+
+		if err == nil {
+			return
+		}
+
+		if onError != nil {
+			onError(handler.primary, record, err)
+		}
+	}
+
+	if !inLevel(handler.secondary, record) {
+		record.count(failedResult)
+		return
+	}
+
+	if err := tryHandle(handler.secondary, record); err != nil {
+		record.count(failedResult)
+		if onError != nil {
+			onError(handler.secondary, record, err)
+		}
+	}
+}
+
+func (handler *FailoverHandler) IsFailed() bool {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.failed
+}
+
+func (handler *FailoverHandler) GetPrimary() Handler {
+	return handler.primary
+}
+
+func (handler *FailoverHandler) GetSecondary() Handler {
+	return handler.secondary
+}
+
+func (handler *FailoverHandler) SetRetryInterval(interval time.Duration) {
+	handler.Lock()
+	handler.retryInterval = interval
+	handler.Unlock()
+}
+
+func (handler *FailoverHandler) GetRetryInterval() time.Duration {
+	return handler.retryInterval
+}
+
+func (handler *FailoverHandler) SetOnError(onError func(handler Handler, record *Record, err error)) {
+	handler.Lock()
+	handler.onError = onError
+	handler.Unlock()
+}
+
+func (handler *FailoverHandler) Flush() error {
+	return flushHandlers([]Handler{handler.primary, handler.secondary})
+}
+
+func (handler *FailoverHandler) Close() error {
+	return closeHandlers([]Handler{handler.primary, handler.secondary})
+}
+
+func NewFailoverHandler(primary, secondary Handler) *FailoverHandler {
+	handler := &FailoverHandler{
+		BaseHandler:   BaseHandler{formatter: primary.GetFormatter()},
+		primary:       primary,
+		secondary:     secondary,
+		retryInterval: 30 * time.Second,
+	}
+	handler.SetAtomicLevel(NewAtomicLevel(primary.GetLevel()))
+
+	return handler
+}
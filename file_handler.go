@@ -24,22 +24,197 @@ package golog
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"os/signal"
 	"path"
+	"sync"
+	"time"
 )
 
-func NewFileHandler(level *Level, formatter *Formatter, filename string) (Handler, error) {
+type FileHandler struct {
+	StreamHandler
+	filename string
+	watched  bool
+	info     os.FileInfo
+}
+
+func (handler *FileHandler) Handle(record *Record) {
+	handler.TryHandle(record)
+}
+
+func (handler *FileHandler) TryHandle(record *Record) error {
+	handler.Lock()
+	defer handler.Unlock()
+
+	if handler.watched && handler.changed() {
+		if err := handler.reopen(); err != nil {
+			return err
+		}
+	}
+
+	return handler.write(record)
+}
+
+func (handler *FileHandler) GetFilename() string {
+	return handler.filename
+}
+
+func (handler *FileHandler) IsWatched() bool {
+	return handler.watched
+}
+
+func (handler *FileHandler) Reopen() error {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.reopen()
+}
+
+func (handler *FileHandler) reopen() error {
+	err := handler.flush()
+
+	file, info, openErr := openFile(handler.filename)
+	if openErr != nil {
+		return openErr
+	}
+
+	if closeErr := handler.stream.Close(); err == nil {
+		err = closeErr
+	}
+
+	handler.stream = file
+	handler.info = info
+	if handler.buffer != nil {
+		handler.buffer.Reset(file)
+	}
+
+	return err
+}
+
+func (handler *FileHandler) changed() bool {
+	info, err := os.Stat(handler.filename)
+	if err != nil {
+		return true
+	}
+
+	return !os.SameFile(info, handler.info)
+}
+
+func openFile(filename string) (*os.File, os.FileInfo, error) {
 	filepath := path.Dir(filename)
 
 	err := os.MkdirAll(filepath, os.ModeDir|os.ModePerm)
 	if err != nil {
-		return nil, errors.New("Cannot create directory: " + filepath)
+		return nil, nil, errors.New("Cannot create directory: " + filepath)
 	}
 
 	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
 	if err != nil {
-		return nil, errors.New("Cannot open file: " + filename)
+		return nil, nil, errors.New("Cannot open file: " + filename)
 	}
 
-	return NewStreamHandler(level, formatter, file), nil
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, errors.New("Cannot stat file: " + filename)
+	}
+
+	return file, info, nil
+}
+
+func NewFileHandler(level *Level, formatter *Formatter, filename string) (Handler, error) {
+	file, info, err := openFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := &FileHandler{
+		StreamHandler: StreamHandler{
+			BaseHandler: BaseHandler{formatter: formatter},
+			stream:      file,
+			flushLevel:  ERROR,
+		},
+		filename: filename,
+		info:     info,
+	}
+	handler.SetAtomicLevel(NewAtomicLevel(level))
+
+	return handler, nil
+}
+
+func NewBufferedFileHandler(level *Level, formatter *Formatter, filename string, size int, interval time.Duration) (Handler, error) {
+	handler, err := NewFileHandler(level, formatter, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	file := handler.(*FileHandler)
+	file.SetBufferSize(size)
+	file.SetFlushInterval(interval)
+
+	return handler, nil
+}
+
+func NewWatchedFileHandler(level *Level, formatter *Formatter, filename string) (Handler, error) {
+	handler, err := NewFileHandler(level, formatter, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	handler.(*FileHandler).watched = true
+
+	return handler, nil
+}
+
+func (registry *Registry) Reopen() error {
+	var result error
+	for _, handler := range walkHandlers(registry.Loggers()) {
+		if reopener, ok := handler.(Reopener); ok {
+			if err := reopener.Reopen(); err != nil && result == nil {
+				result = err
+			}
+		}
+	}
+
+	return result
+}
+
+func (registry *Registry) ReopenOnSignal(sig ...os.Signal) func() {
+	if len(sig) <= 0 {
+		return func() {}
+	}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, sig...)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				if err := registry.Reopen(); err != nil {
+					fmt.Fprintf(os.Stderr, "golog: %v\n", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+func Reopen() error {
+	return DefaultRegistry.Reopen()
+}
+
+func ReopenOnSignal(sig ...os.Signal) func() {
+	return DefaultRegistry.ReopenOnSignal(sig...)
 }
@@ -0,0 +1,195 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func rotate(t *testing.T, filename string) {
+	if err := os.Rename(filename, filename+".1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewFileHandlerError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	handler, err := NewFileHandler(AllLevels, DefaultFormatter, filepath.Join(dir, "file", "main.log"))
+	if err == nil || handler != nil {
+		t.Errorf("expected a nil handler and an error, got %v and %v", handler, err)
+	}
+}
+
+func TestFileHandlerReopen(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "main.log")
+	handler, err := NewFileHandler(AllLevels, NewFormatter("{message}", ""), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := handler.(*FileHandler)
+	defer file.Close()
+
+	file.Handle(newTestRecord(INFO, "before"))
+	rotate(t, filename)
+	file.Handle(newTestRecord(INFO, "moved"))
+
+	if err := file.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	file.Handle(newTestRecord(INFO, "after"))
+
+	if text := readStream(t, filename+".1"); text != "before\nmoved\n" {
+		t.Errorf("unexpected rotated text %q", text)
+	}
+	if text := readStream(t, filename); text != "after\n" {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestBufferedFileHandlerReopenFlushes(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "main.log")
+	handler, err := NewBufferedFileHandler(AllLevels, NewFormatter("{message}", ""), filename, 1024, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := handler.(*FileHandler)
+	defer file.Close()
+
+	file.Handle(newTestRecord(INFO, "buffered"))
+	rotate(t, filename)
+	if err := file.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	if text := readStream(t, filename+".1"); text != "buffered\n" {
+		t.Errorf("expected the buffer to be flushed to the old file, got %q", text)
+	}
+	if file.GetBufferSize() != 1024 {
+		t.Errorf("expected the buffer to be kept, got %d", file.GetBufferSize())
+	}
+}
+
+func TestWatchedFileHandler(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "main.log")
+	handler, err := NewWatchedFileHandler(AllLevels, NewFormatter("{message}", ""), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := handler.(*FileHandler)
+	defer file.Close()
+
+	if !file.IsWatched() || file.GetFilename() != filename {
+		t.Error("expected a watched handler for the file")
+	}
+
+	file.Handle(newTestRecord(INFO, "before"))
+	rotate(t, filename)
+	file.Handle(newTestRecord(INFO, "after"))
+
+	if text := readStream(t, filename+".1"); text != "before\n" {
+		t.Errorf("unexpected rotated text %q", text)
+	}
+	if text := readStream(t, filename); text != "after\n" {
+		t.Errorf("unexpected text %q", text)
+	}
+
+	if err := os.Remove(filename); err != nil {
+		t.Fatal(err)
+	}
+	file.Handle(newTestRecord(INFO, "recreated"))
+	if text := readStream(t, filename); text != "recreated\n" {
+		t.Errorf("expected a deleted file to be recreated, got %q", text)
+	}
+}
+
+func TestReopenOnSignal(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "main.log")
+	handler, err := NewFileHandler(AllLevels, NewFormatter("{message}", ""), filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.(*FileHandler).Close()
+
+	registry := NewRegistry()
+	registry.GetRoot().SetHandlers(handler)
+
+	stop := registry.ReopenOnSignal(syscall.SIGHUP)
+	defer stop()
+
+	rotate(t, filename)
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		t.Skip(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filename); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the file to be reopened on the signal")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stop()
+	stop()
+}
+
+func TestReopenOnSignalWithoutSignals(t *testing.T) {
+	stop := NewRegistry().ReopenOnSignal()
+	stop()
+}
+
+func TestConfigWatchedFileHandler(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "main.log")
+	registry := NewRegistry()
+	err := registry.ApplyConfig(&Config{
+		Formatters: map[string]ConfigFormatter{"default": {Format: "{message}"}},
+		Handlers: map[string]ConfigHandler{
+			"file": {Type: "WatchedFileHandler", Formatter: "default", Properties: map[string]interface{}{"filename": filename, "bufferSize": 512}},
+		},
+		Loggers: map[string]ConfigLogger{"root": {Handlers: []string{"file"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer registry.Close()
+
+	file, ok := registry.GetRoot().GetHandlers()[0].(*FileHandler)
+	if !ok || !file.IsWatched() || file.GetBufferSize() != 512 || file.GetFilename() != filename {
+		t.Errorf("unexpected handler %#v", registry.GetRoot().GetHandlers()[0])
+	}
+}
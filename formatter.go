@@ -23,17 +23,44 @@
 package golog
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"sync"
-	"time"
 )
 
-var DefaultFormatter = NewFormatter("[{time}][{level}][{file}:{line}] {message}", "2006-01-02 15:04:05")
+const defaultFormat = "[{time}][{level}][{file}:{line}] {message}"
+const defaultDateFormat = "2006-01-02 15:04:05"
+const defaultIndent = "\t"
+
+const (
+	MultilineKeep = iota
+	MultilineEscape
+	MultilinePrefix
+	MultilineIndent
+)
+
+var multilineModes = []string{
+	"keep",
+	"escape",
+	"prefix",
+	"indent",
+}
+
+var newlineEscaper = strings.NewReplacer(`\`, `\\`, "\r", `\r`, "\n", `\n`)
+
+var DefaultFormatter = NewFormatter(defaultFormat, defaultDateFormat)
 
 type Formatter struct {
 	sync.Mutex
 	format     string
 	dateFormat string
+	multiline  int
+	indent     string
+	kind       string
+	encode     func(record *Record) string
 }
 
 func (formatter *Formatter) SetFormat(format string) {
@@ -56,30 +83,126 @@ func (formatter *Formatter) GetDateFormat() string {
 	return formatter.dateFormat
 }
 
+func (formatter *Formatter) SetMultiline(multiline int) {
+	formatter.Lock()
+	formatter.multiline = multiline
+	formatter.Unlock()
+}
+
+func (formatter *Formatter) GetMultiline() int {
+	return formatter.multiline
+}
+
+func (formatter *Formatter) SetIndent(indent string) {
+	formatter.Lock()
+	formatter.indent = indent
+	formatter.Unlock()
+}
+
+func (formatter *Formatter) GetIndent() string {
+	return formatter.indent
+}
+
+func (formatter *Formatter) GetType() string {
+	if len(formatter.kind) <= 0 {
+		return "template"
+	}
+
+	return formatter.kind
+}
+
 func (formatter *Formatter) Format(record *Record) string {
+	if formatter.encode != nil {
+		return formatter.encode(record)
+	}
+
+	message, stack := record.message, record.stack
+	if formatter.multiline == MultilineEscape {
+		message, stack = newlineEscaper.Replace(message), newlineEscaper.Replace(stack)
+	}
 
 	replace := strings.NewReplacer(
-		"{logger}", record.logger.name,
-		"{level}", record.level,
-		"{line}", record.line,
-		"{time}", time.Now().Format(formatter.dateFormat),
+		"{logger}", record.GetLoggerName(),
+		"{level}", LevelToString(record.level),
+		"{line}", strconv.Itoa(record.line),
+		"{time}", record.time.Format(formatter.dateFormat),
 		"{file}", record.file,
 		"{path}", record.path,
 		"{function}", record.function,
-		"{message}", record.message,
+		"{message}", message,
+		"{stack}", stack,
+		"{fields}", record.fields.String(),
 	)
 
-	replaced := replace.Replace(formatter.format)
-	if !strings.HasSuffix(replaced, "\n") {
-		replaced += "\n"
+	replaced := strings.TrimSuffix(replace.Replace(formatter.format), "\n")
+
+	switch formatter.multiline {
+	case MultilinePrefix:
+		replaced = strings.Replace(replaced, "\n", "\n"+formatter.header(replace), -1)
+	case MultilineIndent:
+		replaced = strings.Replace(replaced, "\n", "\n"+formatter.indent, -1)
+	}
+
+	return replaced + "\n"
+}
+
+func (formatter *Formatter) header(replace *strings.Replacer) string {
+	header := formatter.format
+	if x := strings.Index(header, "{message}"); x >= 0 {
+		header = header[:x]
+	} else if x := strings.Index(header, "{stack}"); x >= 0 {
+		header = header[:x]
 	}
 
-	return replaced
+	header = replace.Replace(header)
+	if x := strings.LastIndex(header, "\n"); x >= 0 {
+		header = header[x+1:]
+	}
+
+	return header
 }
 
 func NewFormatter(format, dateFormat string) *Formatter {
 	return &Formatter{
 		format:     format,
 		dateFormat: dateFormat,
+		indent:     defaultIndent,
+	}
+}
+
+func NewFormatterFunc(kind string, encode func(record *Record) string) *Formatter {
+	return &Formatter{
+		indent: defaultIndent,
+		kind:   kind,
+		encode: encode,
 	}
 }
+
+func NewJSONFormatter() *Formatter {
+	return NewFormatterFunc("json", func(record *Record) string {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Sprintf("{\"error\":%q}\n", err.Error())
+		}
+
+		return string(data) + "\n"
+	})
+}
+
+func MultilineToString(multiline int) string {
+	if multiline < MultilineKeep || multiline > MultilineIndent {
+		return ""
+	}
+
+	return multilineModes[multiline]
+}
+
+func ParseMultiline(name string) (int, error) {
+	for multiline, mode := range multilineModes {
+		if strings.EqualFold(mode, name) {
+			return multiline, nil
+		}
+	}
+
+	return MultilineKeep, errors.New(fmt.Sprintf("Unknown multiline mode [%s]", name))
+}
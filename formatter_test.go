@@ -0,0 +1,157 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatterMultiline(t *testing.T) {
+	record := newTestRecord(ERROR, "one\ntwo")
+	record.time = time.Date(2014, 11, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		mode     int
+		expected string
+	}{
+		{MultilineKeep, "[15:04:05][ERROR] one\ntwo\n"},
+		{MultilineEscape, "[15:04:05][ERROR] one\\ntwo\n"},
+		{MultilinePrefix, "[15:04:05][ERROR] one\n[15:04:05][ERROR] two\n"},
+		{MultilineIndent, "[15:04:05][ERROR] one\n\ttwo\n"},
+	}
+
+	for _, test := range tests {
+		formatter := NewFormatter("[{time}][{level}] {message}", "15:04:05")
+		formatter.SetMultiline(test.mode)
+
+		if text := formatter.Format(record); text != test.expected {
+			t.Errorf("mode %s: expected %q, got %q", MultilineToString(test.mode), test.expected, text)
+		}
+	}
+}
+
+func TestFormatterEscapesBackslashes(t *testing.T) {
+	formatter := NewFormatter("{message}", "")
+	formatter.SetMultiline(MultilineEscape)
+
+	if text := formatter.Format(newTestRecord(INFO, "C:\\new\r\nline")); text != `C:\\new\r\nline`+"\n" {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestFormatterIndent(t *testing.T) {
+	formatter := NewFormatter("{message}", "")
+	formatter.SetMultiline(MultilineIndent)
+	formatter.SetIndent("  | ")
+
+	if text := formatter.Format(newTestRecord(INFO, "one\ntwo")); text != "one\n  | two\n" {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestParseMultiline(t *testing.T) {
+	for mode := MultilineKeep; mode <= MultilineIndent; mode++ {
+		parsed, err := ParseMultiline(strings.ToUpper(MultilineToString(mode)))
+		if err != nil || parsed != mode {
+			t.Errorf("mode %d: got %d, %v", mode, parsed, err)
+		}
+	}
+
+	if _, err := ParseMultiline("wrap"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+	if name := MultilineToString(MultilineIndent + 1); name != "" {
+		t.Errorf("unexpected name %q", name)
+	}
+}
+
+func TestLoggerStackLevel(t *testing.T) {
+	snapshot := Snapshot()
+	defer Restore(snapshot)
+
+	target := newRecordingHandler()
+	RootLogger.SetHandlers(target)
+	SetLevel(DEBUG)
+
+	Error("without")
+	SetStackLevel(ERROR)
+	Warning("below")
+	Error("with")
+
+	target.Lock()
+	defer target.Unlock()
+	if len(target.records) != 3 {
+		t.Fatalf("unexpected records %v", target.records)
+	}
+	if target.records[0].GetStack() != "" || target.records[1].GetStack() != "" {
+		t.Error("unexpected stack below the stack level")
+	}
+	if stack := target.records[2].GetStack(); !strings.HasPrefix(stack, "github.com/bambocher/golog.TestLoggerStackLevel\n\t") {
+		t.Errorf("expected the stack to start at the caller, got %q", stack)
+	}
+
+	formatter := NewFormatter("{message}\n{stack}", "")
+	formatter.SetMultiline(MultilineIndent)
+	if text := formatter.Format(target.records[2]); !strings.HasPrefix(text, "with\n\tgithub.com/bambocher/golog.TestLoggerStackLevel\n\t\t") {
+		t.Errorf("expected an indented stack, got %q", text)
+	}
+}
+
+func TestConfigMultilineAndStackLevel(t *testing.T) {
+	registry := NewRegistry()
+	err := registry.ApplyConfig(&Config{
+		Formatters: map[string]ConfigFormatter{
+			"indented": {Format: "{message}", Multiline: "indent", Indent: "  "},
+		},
+		Handlers: map[string]ConfigHandler{
+			"null": {Type: "NullHandler", Formatter: "indented"},
+		},
+		Loggers: map[string]ConfigLogger{
+			"app": {StackLevel: "error", Handlers: []string{"null"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := registry.GetLogger("app")
+	if logger.GetStackLevel() != ERROR || registry.GetRoot().GetStackLevel() != CRITICAL+1 {
+		t.Errorf("unexpected stack levels %d and %d", logger.GetStackLevel(), registry.GetRoot().GetStackLevel())
+	}
+
+	formatter := logger.GetHandlers()[0].GetFormatter()
+	if formatter.GetMultiline() != MultilineIndent || formatter.GetIndent() != "  " {
+		t.Errorf("unexpected multiline %d and indent %q", formatter.GetMultiline(), formatter.GetIndent())
+	}
+
+	errs := ValidateConfig(&Config{
+		Formatters: map[string]ConfigFormatter{"wrapped": {Format: "{message}", Multiline: "wrap"}},
+		Loggers:    map[string]ConfigLogger{"root": {StackLevel: "loud", Handlers: []string{}}},
+	})
+	paths := configPaths(errs.(ConfigErrors))
+	if !equalStrings(paths, []string{"formatters.wrapped", "formatters.wrapped.multiline", "loggers.root.stackLevel"}) {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
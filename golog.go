@@ -22,7 +22,7 @@
 
 package golog
 
-var RootLogger = GetLogger("root")
+var RootLogger = DefaultRegistry.GetRoot()
 
 func SetName(name string) {
 	RootLogger.SetName(name)
@@ -40,6 +40,14 @@ func GetLevel() int {
 	return RootLogger.GetLevel()
 }
 
+func SetStackLevel(level int) {
+	RootLogger.SetStackLevel(level)
+}
+
+func GetStackLevel() int {
+	return RootLogger.GetStackLevel()
+}
+
 func SetHandlers(args ...Handler) {
 	RootLogger.SetHandlers(args...)
 }
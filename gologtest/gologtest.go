@@ -0,0 +1,205 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package gologtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bambocher/golog"
+)
+
+type Matcher func(record *golog.Record) bool
+
+func Level(level int) Matcher {
+	return func(record *golog.Record) bool {
+		return record.GetLevel() == level
+	}
+}
+
+func MinLevel(level int) Matcher {
+	return func(record *golog.Record) bool {
+		return record.GetLevel() >= level
+	}
+}
+
+func Logger(name string) Matcher {
+	return func(record *golog.Record) bool {
+		return record.GetLoggerName() == name
+	}
+}
+
+func Message(substring string) Matcher {
+	return func(record *golog.Record) bool {
+		return strings.Contains(record.GetMessage(), substring)
+	}
+}
+
+func File(file string) Matcher {
+	return func(record *golog.Record) bool {
+		return record.GetFile() == file
+	}
+}
+
+func Function(function string) Matcher {
+	return func(record *golog.Record) bool {
+		return record.GetFunction() == function || strings.HasSuffix(record.GetFunction(), "."+function)
+	}
+}
+
+type RecordingHandler struct {
+	golog.BaseHandler
+	records []*golog.Record
+}
+
+func (handler *RecordingHandler) Handle(record *golog.Record) {
+	handler.Lock()
+	handler.records = append(handler.records, record)
+	handler.Unlock()
+}
+
+func (handler *RecordingHandler) Records() []*golog.Record {
+	handler.Lock()
+	defer handler.Unlock()
+
+	records := make([]*golog.Record, len(handler.records))
+	copy(records, handler.records)
+
+	return records
+}
+
+func (handler *RecordingHandler) Find(matchers ...Matcher) []*golog.Record {
+	var found []*golog.Record
+
+	for _, record := range handler.Records() {
+		if match(record, matchers) {
+			found = append(found, record)
+		}
+	}
+
+	return found
+}
+
+func (handler *RecordingHandler) Reset() {
+	handler.Lock()
+	handler.records = nil
+	handler.Unlock()
+}
+
+func NewRecordingHandler() *RecordingHandler {
+	handler := &RecordingHandler{}
+	handler.SetLevel(golog.AllLevels)
+	handler.SetFormatter(golog.DefaultFormatter)
+
+	return handler
+}
+
+type Recorder struct {
+	*RecordingHandler
+	t testing.TB
+}
+
+func (recorder *Recorder) AssertLogged(level int, substring string) {
+	recorder.t.Helper()
+
+	if len(recorder.Find(Level(level), Message(substring))) == 0 {
+		recorder.t.Errorf("expected %s record containing %q, got:\n%s", golog.LevelToString(level), substring, recorder.dump())
+	}
+}
+
+func (recorder *Recorder) AssertNotLogged(level int, substring string) {
+	recorder.t.Helper()
+
+	if len(recorder.Find(Level(level), Message(substring))) != 0 {
+		recorder.t.Errorf("unexpected %s record containing %q, got:\n%s", golog.LevelToString(level), substring, recorder.dump())
+	}
+}
+
+func (recorder *Recorder) AssertNoErrors() {
+	recorder.t.Helper()
+
+	if len(recorder.Find(MinLevel(golog.ERROR))) != 0 {
+		recorder.t.Errorf("unexpected error records, got:\n%s", recorder.dump())
+	}
+}
+
+func (recorder *Recorder) AssertMatch(matchers ...Matcher) {
+	recorder.t.Helper()
+
+	if len(recorder.Find(matchers...)) == 0 {
+		recorder.t.Errorf("expected a record matching all conditions, got:\n%s", recorder.dump())
+	}
+}
+
+func (recorder *Recorder) AssertCount(count int, matchers ...Matcher) {
+	recorder.t.Helper()
+
+	if found := len(recorder.Find(matchers...)); found != count {
+		recorder.t.Errorf("expected %d matching records, found %d, got:\n%s", count, found, recorder.dump())
+	}
+}
+
+func (recorder *Recorder) dump() string {
+	var lines []string
+	for _, record := range recorder.Records() {
+		lines = append(lines, "\t["+record.GetLoggerName()+"]["+record.GetLevelName()+"] "+record.GetMessage())
+	}
+
+	if len(lines) == 0 {
+		return "\t(no records)"
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func New(t testing.TB, loggers ...*golog.Logger) *Recorder {
+	if len(loggers) == 0 {
+		loggers = []*golog.Logger{golog.RootLogger}
+	}
+
+	recorder := &Recorder{
+		RecordingHandler: NewRecordingHandler(),
+		t:                t,
+	}
+
+	for _, logger := range loggers {
+		logger := logger
+		previous := append([]golog.Handler(nil), logger.GetHandlers()...)
+		logger.SetHandlers(recorder.RecordingHandler)
+		t.Cleanup(func() {
+			logger.SetHandlers(previous...)
+		})
+	}
+
+	return recorder
+}
+
+func match(record *golog.Record, matchers []Matcher) bool {
+	for _, matcher := range matchers {
+		if !matcher(record) {
+			return false
+		}
+	}
+
+	return true
+}
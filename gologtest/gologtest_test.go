@@ -0,0 +1,97 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package gologtest
+
+import (
+	"testing"
+
+	"github.com/bambocher/golog"
+)
+
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, format)
+}
+
+func TestRecorderCapturesLogger(t *testing.T) {
+	logger := golog.GetLogger("gologtest.capture")
+	recorder := New(t, logger)
+
+	logger.Info("hello %s", "world")
+	logger.Error("failed")
+
+	recorder.AssertLogged(golog.INFO, "hello world")
+	recorder.AssertNotLogged(golog.DEBUG, "hello")
+	recorder.AssertCount(1, MinLevel(golog.ERROR))
+	recorder.AssertMatch(Logger("gologtest.capture"), Message("failed"))
+}
+
+func TestRecorderRestoresHandlers(t *testing.T) {
+	logger := golog.GetLogger("gologtest.restore")
+	handler := NewRecordingHandler()
+	logger.SetHandlers(handler)
+
+	t.Run("inner", func(t *testing.T) {
+		New(t, logger)
+		logger.Info("captured")
+	})
+
+	logger.Info("after")
+
+	if records := handler.Records(); len(records) != 1 || records[0].GetMessage() != "after" {
+		t.Errorf("handlers not restored, got %d records", len(records))
+	}
+}
+
+func TestRecorderReportsFailures(t *testing.T) {
+	logger := golog.GetLogger("gologtest.failures")
+	fake := &fakeT{TB: t}
+	recorder := &Recorder{RecordingHandler: NewRecordingHandler(), t: fake}
+	logger.SetHandlers(recorder.RecordingHandler)
+
+	logger.Error("unexpected")
+
+	recorder.AssertLogged(golog.INFO, "missing")
+	recorder.AssertNoErrors()
+	recorder.AssertCount(2)
+
+	if len(fake.errors) != 3 {
+		t.Errorf("expected 3 failures, got %d", len(fake.errors))
+	}
+}
+
+func TestRecordingHandlerReset(t *testing.T) {
+	handler := NewRecordingHandler()
+	handler.Handle(golog.MakeRecord(golog.RecordInfo{Level: golog.INFO, Message: "one"}))
+	handler.Reset()
+
+	if records := handler.Records(); len(records) != 0 {
+		t.Errorf("expected no records after reset, got %d", len(records))
+	}
+}
@@ -22,7 +22,13 @@
 
 package golog
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
 
 type Handler interface {
 	SetLevel(level *Level)
@@ -32,20 +38,76 @@ type Handler interface {
 	Handle(record *Record)
 }
 
+type TryHandler interface {
+	TryHandle(record *Record) error
+}
+
+type Flusher interface {
+	Flush() error
+}
+
+type Closer interface {
+	Close() error
+}
+
+type Reopener interface {
+	Reopen() error
+}
+
 type BaseHandler struct {
+	written uint64
 	sync.Mutex
-	level     *Level
+	name      string
+	level     atomic.Value
 	formatter *Formatter
 }
 
-func (handler *BaseHandler) SetLevel(level *Level) {
+func (handler *BaseHandler) SetName(name string) {
 	handler.Lock()
-	handler.level = level
+	handler.name = name
 	handler.Unlock()
 }
 
+func (handler *BaseHandler) GetName() string {
+	return handler.name
+}
+
+func (handler *BaseHandler) GetBytesWritten() uint64 {
+	return atomic.LoadUint64(&handler.written)
+}
+
+func (handler *BaseHandler) addWritten(size int) {
+	atomic.AddUint64(&handler.written, uint64(size))
+}
+
+func (handler *BaseHandler) SetLevel(level *Level) {
+	handler.GetAtomicLevel().SetLevel(level)
+}
+
 func (handler *BaseHandler) GetLevel() *Level {
-	return handler.level
+	return handler.GetAtomicLevel().GetLevel()
+}
+
+func (handler *BaseHandler) SetAtomicLevel(level *AtomicLevel) {
+	if level == nil {
+		level = NewAtomicLevel(AllLevels)
+	}
+
+	handler.level.Store(level)
+}
+
+func (handler *BaseHandler) GetAtomicLevel() *AtomicLevel {
+	if level, ok := handler.level.Load().(*AtomicLevel); ok {
+		return level
+	}
+
+	handler.level.CompareAndSwap(nil, NewAtomicLevel(AllLevels))
+
+	return handler.level.Load().(*AtomicLevel)
+}
+
+func (handler *BaseHandler) Enabled(level int) bool {
+	return handler.GetAtomicLevel().Enabled(level)
 }
 
 func (handler *BaseHandler) SetFormatter(formater *Formatter) {
@@ -59,3 +121,107 @@ func (handler *BaseHandler) GetFormatter() *Formatter {
 }
 
 func (handler *BaseHandler) Handle(record *Record) {}
+
+func dispatch(handler Handler, record *Record) {
+	if inLevel(handler, record) {
+		handler.Handle(record)
+	}
+}
+
+func tryHandle(handler Handler, record *Record) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.New(fmt.Sprintf("Handler %T panicked: %v", handler, recovered))
+		}
+	}()
+
+	if tryHandler, ok := handler.(TryHandler); ok {
+		return tryHandler.TryHandle(record)
+	}
+
+	handler.Handle(record)
+
+	return nil
+}
+
+func inLevel(handler Handler, record *Record) bool {
+	if enabler, ok := handler.(interface{ Enabled(level int) bool }); ok {
+		return enabler.Enabled(record.level)
+	}
+
+	handlerLevel := handler.GetLevel()
+	return handlerLevel.Min <= record.level && record.level <= handlerLevel.Max
+}
+
+func handlerChildren(handler Handler) []Handler {
+	switch parent := handler.(type) {
+	case interface{ GetHandlers() []Handler }:
+		return parent.GetHandlers()
+	case interface{ GetHandler() Handler }:
+		return []Handler{parent.GetHandler()}
+	case *FailoverHandler:
+		return []Handler{parent.GetPrimary(), parent.GetSecondary()}
+	case *MemoryHandler:
+		if target := parent.GetTarget(); target != nil {
+			return []Handler{target}
+		}
+	}
+
+	return nil
+}
+
+func (registry *Registry) handlerName(handler Handler) string {
+	if name, ok := registry.knownHandlerName(handler); ok {
+		return name
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	name, ok := registry.handlerNames[handler]
+	if !ok {
+		kind := strings.ToLower(handlerType(handler))
+		registry.handlerCounts[kind]++
+		name = fmt.Sprintf("%s%d", kind, registry.handlerCounts[kind])
+		registry.handlerNames[handler] = name
+	}
+
+	return name
+}
+
+func (registry *Registry) knownHandlerName(handler Handler) (string, bool) {
+	if named, ok := handler.(interface{ GetName() string }); ok && len(named.GetName()) > 0 {
+		return named.GetName(), true
+	}
+
+	switch handler {
+	case registry.stdout:
+		return "stdout", true
+	case registry.stderr:
+		return "stderr", true
+	case registry.null:
+		return "null", true
+	}
+
+	return "", false
+}
+
+func (registry *Registry) forgetHandlers(handlers []Handler) {
+	active := make(map[Handler]bool, len(handlers))
+	for _, handler := range handlers {
+		active[handler] = true
+	}
+
+	registry.Lock()
+	for handler := range registry.handlerNames {
+		if !active[handler] {
+			delete(registry.handlerNames, handler)
+		}
+	}
+	registry.Unlock()
+}
+
+func handlerType(handler Handler) string {
+	name := fmt.Sprintf("%T", handler)
+	return name[strings.LastIndex(name, ".")+1:]
+}
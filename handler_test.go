@@ -0,0 +1,82 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import "testing"
+
+type recordingHandler struct {
+	BaseHandler
+	records []*Record
+}
+
+func (handler *recordingHandler) Handle(record *Record) {
+	handler.Lock()
+	handler.records = append(handler.records, record)
+	handler.Unlock()
+}
+
+func (handler *recordingHandler) messages() []string {
+	handler.Lock()
+	defer handler.Unlock()
+
+	messages := make([]string, len(handler.records))
+	for x, record := range handler.records {
+		messages[x] = record.message
+	}
+
+	return messages
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{BaseHandler: BaseHandler{formatter: DefaultFormatter}}
+}
+
+func newTestRecord(level int, message string) *Record {
+	return MakeRecord(RecordInfo{Level: level, Message: message, Path: "main.go", Line: 1})
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for x := range a {
+		if a[x] != b[x] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestDispatchRespectsLevel(t *testing.T) {
+	target := newRecordingHandler()
+	target.SetLevel(ErrorLevels)
+
+	dispatch(target, newTestRecord(INFO, "info"))
+	dispatch(target, newTestRecord(ERROR, "error"))
+
+	if messages := target.messages(); !equalStrings(messages, []string{"error"}) {
+		t.Errorf("unexpected records %v", messages)
+	}
+}
@@ -0,0 +1,389 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	JSONArrayEncoding = iota
+	NDJSONEncoding
+)
+
+type HTTPHandler struct {
+	BaseHandler
+	url        string
+	client     *http.Client
+	header     http.Header
+	encoding   int
+	gzip       bool
+	maxCount   int
+	maxBytes   int
+	maxLatency time.Duration
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	maxQueue   int
+	batch      [][]byte
+	batchBytes int
+	timer      *time.Timer
+	closed     bool
+	queue      [][][]byte
+	inflight   int
+	cond       *sync.Cond
+	done       chan struct{}
+	errMutex   sync.Mutex
+	err        error
+}
+
+type httpRequest struct {
+	url        string
+	client     *http.Client
+	header     http.Header
+	encoding   int
+	gzip       bool
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+func (handler *HTTPHandler) Handle(record *Record) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		handler.setError(err)
+		return
+	}
+
+	handler.Lock()
+	defer handler.Unlock()
+
+	if handler.closed {
+		return
+	}
+
+	handler.batch = append(handler.batch, data)
+	handler.batchBytes += len(data)
+
+	if len(handler.batch) >= handler.maxCount || handler.batchBytes >= handler.maxBytes {
+		handler.enqueue()
+	} else if len(handler.batch) == 1 && handler.maxLatency > 0 {
+		handler.timer = time.AfterFunc(handler.maxLatency, func() {
+			handler.Lock()
+			handler.enqueue()
+			handler.Unlock()
+		})
+	}
+}
+
+func (handler *HTTPHandler) Flush() error {
+	handler.Lock()
+	handler.enqueue()
+	for handler.inflight > 0 {
+		handler.cond.Wait()
+	}
+	handler.Unlock()
+
+	handler.errMutex.Lock()
+	defer handler.errMutex.Unlock()
+
+	err := handler.err
+	handler.err = nil
+
+	return err
+}
+
+func (handler *HTTPHandler) Close() error {
+	err := handler.Flush()
+
+	handler.Lock()
+	handler.closed = true
+	handler.cond.Broadcast()
+	handler.Unlock()
+
+	<-handler.done
+
+	return err
+}
+
+func (handler *HTTPHandler) enqueue() {
+	if handler.timer != nil {
+		handler.timer.Stop()
+		handler.timer = nil
+	}
+
+	if len(handler.batch) == 0 || handler.closed {
+		return
+	}
+
+	if len(handler.queue) >= handler.maxQueue {
+		handler.setError(errors.New(fmt.Sprintf("Queue of [%s] is full, %d records dropped", handler.url, len(handler.queue[0]))))
+		handler.queue[0] = nil
+		handler.queue = handler.queue[1:]
+		handler.inflight--
+	}
+
+	handler.queue = append(handler.queue, handler.batch)
+	handler.inflight++
+	handler.batch = nil
+	handler.batchBytes = 0
+	handler.cond.Broadcast()
+}
+
+func (handler *HTTPHandler) run() {
+	defer close(handler.done)
+
+	handler.Lock()
+	defer handler.Unlock()
+
+	for {
+		for len(handler.queue) == 0 && !handler.closed {
+			handler.cond.Wait()
+		}
+		if len(handler.queue) == 0 {
+			return
+		}
+
+		batch := handler.queue[0]
+		handler.queue[0] = nil
+		handler.queue = handler.queue[1:]
+		request := handler.request()
+		handler.Unlock()
+
+		err := handler.send(request, batch)
+
+		handler.Lock()
+		if err != nil {
+			handler.setError(err)
+		}
+		handler.inflight--
+		handler.cond.Broadcast()
+	}
+}
+
+func (handler *HTTPHandler) request() *httpRequest {
+	return &httpRequest{
+		url:        handler.url,
+		client:     handler.client,
+		header:     handler.header.Clone(),
+		encoding:   handler.encoding,
+		gzip:       handler.gzip,
+		maxRetries: handler.maxRetries,
+		minBackoff: handler.minBackoff,
+		maxBackoff: handler.maxBackoff,
+	}
+}
+
+func (handler *HTTPHandler) send(request *httpRequest, batch [][]byte) error {
+	body, err := request.encode(batch)
+	if err != nil {
+		return err
+	}
+
+	backoff := request.minBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := request.post(body)
+		if err == nil {
+			handler.addWritten(len(body))
+			return nil
+		}
+		if !retry || attempt >= request.maxRetries {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > request.maxBackoff {
+			backoff = request.maxBackoff
+		}
+	}
+}
+
+func (request *httpRequest) post(body []byte) (bool, error) {
+	post, err := http.NewRequest("POST", request.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	for key, values := range request.header {
+		post.Header[key] = values
+	}
+	if request.encoding == NDJSONEncoding {
+		post.Header.Set("Content-Type", "application/x-ndjson")
+	} else {
+		post.Header.Set("Content-Type", "application/json")
+	}
+	if request.gzip {
+		post.Header.Set("Content-Encoding", "gzip")
+	}
+
+	response, err := request.client.Do(post)
+	if err != nil {
+		return true, errors.New(fmt.Sprintf("Can't post records to [%s]: %v", request.url, err))
+	}
+	io.Copy(io.Discard, response.Body)
+	response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = errors.New(fmt.Sprintf("Can't post records to [%s]: %s", request.url, response.Status))
+	retry := response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusRequestTimeout
+
+	return retry, err
+}
+
+func (request *httpRequest) encode(batch [][]byte) ([]byte, error) {
+	var body bytes.Buffer
+	var writer io.Writer = &body
+
+	var compressor *gzip.Writer
+	if request.gzip {
+		compressor = gzip.NewWriter(&body)
+		writer = compressor
+	}
+
+	if request.encoding == NDJSONEncoding {
+		for _, data := range batch {
+			writer.Write(data)
+			writer.Write([]byte("\n"))
+		}
+	} else {
+		writer.Write([]byte("["))
+		for x, data := range batch {
+			if x > 0 {
+				writer.Write([]byte(","))
+			}
+			writer.Write(data)
+		}
+		writer.Write([]byte("]"))
+	}
+
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	return body.Bytes(), nil
+}
+
+func (handler *HTTPHandler) setError(err error) {
+	handler.errMutex.Lock()
+	handler.err = err
+	handler.errMutex.Unlock()
+}
+
+func (handler *HTTPHandler) GetURL() string {
+	return handler.url
+}
+
+func (handler *HTTPHandler) SetClient(client *http.Client) {
+	handler.Lock()
+	handler.client = client
+	handler.Unlock()
+}
+
+func (handler *HTTPHandler) SetHeader(key, value string) {
+	handler.Lock()
+	handler.header.Set(key, value)
+	handler.Unlock()
+}
+
+func (handler *HTTPHandler) SetEncoding(encoding int) {
+	handler.Lock()
+	handler.encoding = encoding
+	handler.Unlock()
+}
+
+func (handler *HTTPHandler) GetEncoding() int {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.encoding
+}
+
+func (handler *HTTPHandler) SetGzip(enabled bool) {
+	handler.Lock()
+	handler.gzip = enabled
+	handler.Unlock()
+}
+
+func (handler *HTTPHandler) SetBatchSize(count, size int) {
+	handler.Lock()
+	handler.maxCount = count
+	handler.maxBytes = size
+	handler.Unlock()
+}
+
+func (handler *HTTPHandler) SetMaxLatency(latency time.Duration) {
+	handler.Lock()
+	handler.maxLatency = latency
+	handler.Unlock()
+}
+
+func (handler *HTTPHandler) SetRetries(retries int) {
+	handler.Lock()
+	handler.maxRetries = retries
+	handler.Unlock()
+}
+
+func (handler *HTTPHandler) SetBackoff(min, max time.Duration) {
+	handler.Lock()
+	handler.minBackoff = min
+	handler.maxBackoff = max
+	handler.Unlock()
+}
+
+func NewHTTPHandler(level *Level, formatter *Formatter, url string) *HTTPHandler {
+	handler := &HTTPHandler{
+		BaseHandler: BaseHandler{formatter: formatter},
+		url:         url,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		header:      make(http.Header),
+		encoding:    JSONArrayEncoding,
+		maxCount:    100,
+		maxBytes:    1 << 20,
+		maxLatency:  time.Second,
+		maxRetries:  3,
+		minBackoff:  100 * time.Millisecond,
+		maxBackoff:  10 * time.Second,
+		maxQueue:    16,
+		done:        make(chan struct{}),
+	}
+	handler.SetAtomicLevel(NewAtomicLevel(level))
+	handler.cond = sync.NewCond(&handler.Mutex)
+
+	go handler.run()
+
+	return handler
+}
@@ -0,0 +1,225 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type httpCollector struct {
+	sync.Mutex
+	bodies   []string
+	messages []string
+	failures int
+}
+
+func (collector *httpCollector) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	collector.Lock()
+	defer collector.Unlock()
+
+	if collector.failures > 0 {
+		collector.failures--
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var reader io.Reader = request.Body
+	if request.Header.Get("Content-Encoding") == "gzip" {
+		reader, _ = gzip.NewReader(request.Body)
+	}
+	body, _ := io.ReadAll(reader)
+	collector.bodies = append(collector.bodies, string(body))
+
+	var records []map[string]interface{}
+	if request.Header.Get("Content-Type") == "application/x-ndjson" {
+		for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+			record := make(map[string]interface{})
+			json.Unmarshal([]byte(line), &record)
+			records = append(records, record)
+		}
+	} else {
+		json.Unmarshal(body, &records)
+	}
+
+	for _, record := range records {
+		collector.messages = append(collector.messages, record["message"].(string))
+	}
+}
+
+func (collector *httpCollector) received() ([]string, int) {
+	collector.Lock()
+	defer collector.Unlock()
+
+	return append([]string(nil), collector.messages...), len(collector.bodies)
+}
+
+func newTestHTTPHandler(t *testing.T, collector http.Handler) *HTTPHandler {
+	server := httptest.NewServer(collector)
+	t.Cleanup(server.Close)
+
+	handler := NewHTTPHandler(AllLevels, DefaultFormatter, server.URL)
+	handler.SetBackoff(time.Millisecond, 10*time.Millisecond)
+	t.Cleanup(func() { handler.Close() })
+
+	return handler
+}
+
+func TestHTTPHandlerBatches(t *testing.T) {
+	collector := &httpCollector{}
+	handler := newTestHTTPHandler(t, collector)
+	handler.SetBatchSize(2, 1<<20)
+	handler.SetMaxLatency(0)
+
+	for _, message := range []string{"one", "two", "three"} {
+		handler.Handle(newTestRecord(INFO, message))
+	}
+
+	if err := handler.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, requests := collector.received()
+	if !equalStrings(messages, []string{"one", "two", "three"}) || requests != 2 {
+		t.Errorf("unexpected %d requests with %v", requests, messages)
+	}
+}
+
+func TestHTTPHandlerNDJSONGzip(t *testing.T) {
+	collector := &httpCollector{}
+	handler := newTestHTTPHandler(t, collector)
+	handler.SetEncoding(NDJSONEncoding)
+	handler.SetGzip(true)
+
+	handler.Handle(newTestRecord(INFO, "one"))
+	handler.Handle(newTestRecord(ERROR, "two"))
+
+	if err := handler.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if messages, _ := collector.received(); !equalStrings(messages, []string{"one", "two"}) {
+		t.Errorf("unexpected messages %v", messages)
+	}
+}
+
+func TestHTTPHandlerMaxLatency(t *testing.T) {
+	collector := &httpCollector{}
+	handler := newTestHTTPHandler(t, collector)
+	handler.SetMaxLatency(10 * time.Millisecond)
+
+	handler.Handle(newTestRecord(INFO, "late"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if messages, _ := collector.received(); len(messages) == 1 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Error("batch was not sent after max latency")
+}
+
+func TestHTTPHandlerRetries(t *testing.T) {
+	collector := &httpCollector{failures: 2}
+	handler := newTestHTTPHandler(t, collector)
+
+	handler.Handle(newTestRecord(INFO, "retried"))
+
+	if err := handler.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if messages, _ := collector.received(); !equalStrings(messages, []string{"retried"}) {
+		t.Errorf("unexpected messages %v", messages)
+	}
+
+	collector.Lock()
+	collector.failures = 10
+	collector.Unlock()
+
+	handler.Handle(newTestRecord(INFO, "lost"))
+	if err := handler.Flush(); err == nil {
+		t.Error("expected an error after retries are exhausted")
+	}
+}
+
+func TestHTTPHandlerDoesNotBlockOnSlowCollector(t *testing.T) {
+	release := make(chan struct{})
+	slow := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		<-release
+	})
+	handler := newTestHTTPHandler(t, slow)
+	handler.SetBatchSize(1, 1<<20)
+
+	start := time.Now()
+	for x := 0; x < 100; x++ {
+		handler.Handle(newTestRecord(INFO, "message"))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Handle blocked for %v", elapsed)
+	}
+
+	close(release)
+	if err := handler.Flush(); err == nil || !strings.Contains(err.Error(), "dropped") {
+		t.Errorf("expected a dropped records error, got %v", err)
+	}
+}
+
+func TestHTTPHandlerConcurrentFlush(t *testing.T) {
+	collector := &httpCollector{}
+	handler := newTestHTTPHandler(t, collector)
+	handler.SetBatchSize(3, 1<<20)
+	handler.SetMaxLatency(time.Millisecond)
+
+	var wait sync.WaitGroup
+	for x := 0; x < 8; x++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for y := 0; y < 50; y++ {
+				handler.Handle(newTestRecord(INFO, "message"))
+				if y%10 == 0 {
+					handler.Flush()
+				}
+			}
+		}()
+	}
+	wait.Wait()
+
+	if err := handler.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if messages, _ := collector.received(); len(messages) != 400 {
+		t.Errorf("expected 400 messages, got %d", len(messages))
+	}
+}
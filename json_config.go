@@ -26,99 +26,279 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
+	"io"
+	"sort"
+	"time"
 )
 
 func LoadJSONConfig(filename string) error {
-	if len(filename) <= 0 {
-		return errors.New("Empty filename")
-	}
+	return DefaultRegistry.LoadJSONConfig(filename)
+}
 
-	file, err := os.Open(filename)
+func (registry *Registry) LoadJSONConfig(filename string) error {
+	config, err := registry.readConfig(filename, "json")
 	if err != nil {
-		return errors.New(fmt.Sprintf("Can't load json config file [%s]: %v", filename, err))
+		return err
 	}
-	defer file.Close()
 
-	config := Config{}
-	err = json.NewDecoder(file).Decode(&config)
-	if err != nil {
-		return errors.New(fmt.Sprintf("Can't parse json config file [%s]: %v", filename, err))
+	return registry.ApplyConfig(config)
+}
+
+func ReadJSONConfig(filename string) (*Config, error) {
+	return DefaultRegistry.readConfig(filename, "json")
+}
+
+func parseJSONConfig(data []byte) (map[string]interface{}, error) {
+	raw := make(map[string]interface{})
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
 	}
 
-	formatters := make(map[string]*Formatter)
-	handlers := make(map[string]Handler)
+	return raw, nil
+}
 
+func ApplyConfig(config *Config) error {
+	return DefaultRegistry.ApplyConfig(config)
+}
+
+func (registry *Registry) ApplyConfig(config *Config) error {
+	if errs := registry.validateConfig(config); errs.HasErrors() {
+		return errs
+	}
+
+	var redactors []Redactor
+	if config.Redaction != nil {
+		var err error
+		redactors, err = newConfigRedactors(config.Redaction)
+		if err != nil {
+			return &ConfigError{Path: "redaction", Message: err.Error()}
+		}
+	}
+
+	var defaults *Formatter
+	formatters := make(map[string]*Formatter)
 	for key, value := range config.Formatters {
-		formatters[key] = NewFormatter(value.Format, value.DateFormat)
+		formatter, err := NewConfigFormatter(value)
+		if err != nil {
+			return &ConfigError{Path: configPath("formatters", key), Message: err.Error()}
+		}
+		if key == "default" && formatter.encode == nil {
+			defaults = formatter
+			formatter = registry.formatter
+		}
+		formatters[key] = formatter
 	}
 
-	for key, value := range config.Handlers {
-		switch value.Type {
+	keys := make([]string, 0, len(config.Handlers))
+	for key := range config.Handlers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-		case "StreamHandler":
-			level := &Level{LevelToInt(value.Level.Min), LevelToInt(value.Level.Max)}
-			formatter, ok := formatters[value.Formatter]
-			if !ok {
-				return errors.New(fmt.Sprintf("Not found formatter [%s] for handler [%s]", value.Formatter, key))
-				break
-			}
+	handlers := make(map[string]Handler)
+	var created []Handler
+	var defaultChanges []func()
+	for _, key := range keys {
+		handler, err := registry.newConfigHandler(key, config.Handlers[key], formatters, &defaultChanges)
+		if err != nil {
+			closeHandlers(created)
+			return &ConfigError{Path: configPath("handlers", key), Message: err.Error()}
+		}
+		handlers[key] = handler
+		if !registry.isDefaultHandler(handler) {
+			created = append(created, handler)
+		}
+	}
 
-			property, ok := value.Properties["stream"]
-			if !ok {
-				return errors.New(fmt.Sprintf("Not found property [stream] for handler [%s]", key))
+	samplers := make(map[string]*Sampler)
+	for key, value := range config.Loggers {
+		if value.Sampling != nil {
+			sampler, err := newConfigSampler(value.Sampling)
+			if err != nil {
+				closeHandlers(created)
+				return &ConfigError{Path: configPath("loggers", key, "sampling"), Message: err.Error()}
 			}
+			samplers[key] = sampler
+		}
+	}
 
-			switch property {
-			case "os.Stdout":
-				handlers[key] = NewStreamHandler(level, formatter, os.Stdout)
-				break
-			case "os.Stderr":
-				handlers[key] = NewStreamHandler(level, formatter, os.Stderr)
-				break
-			default:
-				return errors.New(fmt.Sprintf("Unknown stream [%s] for handler [%s]", property, key))
-			}
-			break
-		case "FileHandler":
-			level := &Level{LevelToInt(value.Level.Min), LevelToInt(value.Level.Max)}
+	if config.Redaction != nil {
+		registry.SetRedactors(redactors...)
+	}
+
+	for _, change := range defaultChanges {
+		change()
+	}
+
+	if defaults != nil {
+		registry.formatter.SetFormat(defaults.GetFormat())
+		registry.formatter.SetDateFormat(defaults.GetDateFormat())
+		registry.formatter.SetMultiline(defaults.GetMultiline())
+		registry.formatter.SetIndent(defaults.GetIndent())
+	}
+
+	for key, value := range config.Loggers {
+		logger := registry.GetLogger(key)
+		level := &Level{LevelToInt(value.Level), CRITICAL}
+		if len(value.MaxLevel) > 0 {
+			level.Max = LevelToInt(value.MaxLevel)
+		}
+		logger.GetAtomicLevel().SetLevel(level)
+		if len(value.StackLevel) > 0 {
+			logger.SetStackLevel(LevelToInt(value.StackLevel))
+		} else {
+			logger.SetStackLevel(CRITICAL + 1)
+		}
+		logger.SetSampler(samplers[key])
+
+		loggerHandlers := make([]Handler, len(value.Handlers))
+		for x, name := range value.Handlers {
+			loggerHandlers[x] = handlers[name]
+		}
+		logger.SetHandlers(loggerHandlers...)
+	}
+
+	return nil
+}
+
+func WriteJSONConfig(writer io.Writer) error {
+	return DefaultRegistry.WriteJSONConfig(writer)
+}
+
+func (registry *Registry) WriteJSONConfig(writer io.Writer) error {
+	config, err := registry.ExportConfig()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(config, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(append(data, '\n'))
+
+	return err
+}
+
+func (registry *Registry) isDefaultHandler(handler Handler) bool {
+	return handler == registry.stdout || handler == registry.stderr || handler == registry.null
+}
+
+func (registry *Registry) newConfigHandler(key string, value ConfigHandler, formatters map[string]*Formatter, defaultChanges *[]func()) (Handler, error) {
+	info := registry.getHandlerType(value.Type)
+	if info == nil {
+		return nil, errors.New(fmt.Sprintf("Unknown handler type [%s]", value.Type))
+	}
+
+	formatter, ok := formatters[value.Formatter]
+	if !ok {
+		formatter = registry.formatter
+	}
 
-			property, ok := value.Properties["filename"]
-			if !ok {
-				return errors.New(fmt.Sprintf("Not found property [filename] for handler [%s]", key))
+	handler, err := info.factory(value.Properties, configLevel(value), formatter)
+	if err != nil {
+		return nil, err
+	}
+
+	if registry.isDefaultHandler(handler) {
+		shared := handler
+		*defaultChanges = append(*defaultChanges, func() {
+			level := shared.GetLevel()
+			if len(value.Level.Min) > 0 {
+				level.Min = LevelToInt(value.Level.Min)
 			}
+			if len(value.Level.Max) > 0 {
+				level.Max = LevelToInt(value.Level.Max)
+			}
+			shared.SetLevel(level)
 
-			handlers[key], err = NewFileHandler(level, formatters[value.Formatter], property)
-			if err != nil {
-				return err
+			if len(value.Formatter) > 0 {
+				shared.SetFormatter(formatter)
 			}
-			break
-		case "NullHandler":
-			handlers[key] = NullHandler
-			break
-		case "StdoutHandler":
-			handlers[key] = StdoutHandler
-			break
-		case "StderrHandler":
-			handlers[key] = StderrHandler
-			break
-		default:
-			return errors.New(fmt.Sprintf("Unknown handler type [%s]", value.Type))
+		})
+	}
+
+	if named, ok := handler.(interface{ SetName(name string) }); ok && !registry.isDefaultHandler(handler) {
+		named.SetName(key)
+	}
+
+	if value.Redaction != nil {
+		redactors, err := newConfigRedactors(value.Redaction)
+		if err != nil {
+			closeHandlers([]Handler{handler})
+			return nil, err
 		}
+		handler = NewRedactingHandler(handler, redactors...)
 	}
 
-	for key, value := range config.Loggers {
-		logger := GetLogger(key)
-		logger.SetLevel(LevelToInt(value.Level))
-		logger.SetHandlers()
-		for x := range value.Handlers {
-			handler, ok := handlers[value.Handlers[x]]
-			if !ok {
-				return errors.New(fmt.Sprintf("Not found handler [%s] for logger [%s]", value.Handlers[x], key))
-			}
-			logger.AddHandlers(handler)
+	if value.Sampling != nil {
+		sampler, err := newConfigSampler(value.Sampling)
+		if err != nil {
+			closeHandlers([]Handler{handler})
+			return nil, err
 		}
+		handler = NewSamplingHandler(handler, sampler)
 	}
 
-	return nil
+	for _, filter := range value.Filters {
+		filterInfo := getFilterType(filter.Type)
+		if filterInfo == nil {
+			closeHandlers([]Handler{handler})
+			return nil, errors.New(fmt.Sprintf("Unknown filter type [%s]", filter.Type))
+		}
+
+		filtered, err := filterInfo.factory(handler, filter.Properties)
+		if err != nil {
+			closeHandlers([]Handler{handler})
+			return nil, err
+		}
+		handler = filtered
+	}
+
+	return handler, nil
+}
+
+func newConfigSampler(config *ConfigSampling) (*Sampler, error) {
+	interval, err := time.ParseDuration(config.Interval)
+	if err != nil {
+		return nil, err
+	}
+
+	if interval <= 0 {
+		return nil, errors.New(fmt.Sprintf("Interval must be positive, got [%s]", config.Interval))
+	}
+
+	return NewSampler(interval, config.First, config.Thereafter), nil
+}
+
+func newConfigRedactors(config *ConfigRedaction) ([]Redactor, error) {
+	var redactors []Redactor
+
+	for _, name := range config.Builtins {
+		redactor, ok := GetBuiltinRedactor(name)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("Unknown builtin redactor [%s]", name))
+		}
+		redactors = append(redactors, redactor)
+	}
+
+	for _, pattern := range config.Patterns {
+		replacement := pattern.Replacement
+		if len(replacement) <= 0 {
+			replacement = RedactedMask
+		}
+
+		redactor, err := NewRegexRedactor(pattern.Pattern, replacement)
+		if err != nil {
+			return nil, err
+		}
+		redactors = append(redactors, redactor)
+	}
+
+	if len(config.Fields) > 0 {
+		redactors = append(redactors, NewFieldRedactor(config.Fields...))
+	}
+
+	return redactors, nil
 }
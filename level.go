@@ -22,7 +22,14 @@
 
 package golog
 
-import "strings"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
 
 const (
 	DEBUG = iota
@@ -60,6 +67,127 @@ type Level struct {
 	Max int
 }
 
+type AtomicLevel struct {
+	value int64
+}
+
+func (level *AtomicLevel) SetLevel(value *Level) {
+	atomic.StoreInt64(&level.value, packLevel(value.Min, value.Max))
+}
+
+func (level *AtomicLevel) GetLevel() *Level {
+	min, max := unpackLevel(atomic.LoadInt64(&level.value))
+	return &Level{min, max}
+}
+
+func (level *AtomicLevel) SetMin(min int) {
+	for {
+		old := atomic.LoadInt64(&level.value)
+		_, max := unpackLevel(old)
+		if atomic.CompareAndSwapInt64(&level.value, old, packLevel(min, max)) {
+			return
+		}
+	}
+}
+
+func (level *AtomicLevel) GetMin() int {
+	min, _ := unpackLevel(atomic.LoadInt64(&level.value))
+	return min
+}
+
+func (level *AtomicLevel) SetMax(max int) {
+	for {
+		old := atomic.LoadInt64(&level.value)
+		min, _ := unpackLevel(old)
+		if atomic.CompareAndSwapInt64(&level.value, old, packLevel(min, max)) {
+			return
+		}
+	}
+}
+
+func (level *AtomicLevel) GetMax() int {
+	_, max := unpackLevel(atomic.LoadInt64(&level.value))
+	return max
+}
+
+func (level *AtomicLevel) Enabled(value int) bool {
+	min, max := unpackLevel(atomic.LoadInt64(&level.value))
+	return min <= value && value <= max
+}
+
+func (level *AtomicLevel) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	type payload struct {
+		Level string `json:"level,omitempty"`
+		Min   string `json:"min"`
+		Max   string `json:"max"`
+	}
+
+	switch request.Method {
+	case "GET":
+	case "PUT":
+		var body payload
+		err := json.NewDecoder(request.Body).Decode(&body)
+		if err != nil {
+			http.Error(writer, fmt.Sprintf("Can't parse level: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		value := level.GetLevel()
+		for _, field := range []struct {
+			name   string
+			target *int
+		}{{body.Level, &value.Min}, {body.Min, &value.Min}, {body.Max, &value.Max}} {
+			if len(field.name) <= 0 {
+				continue
+			}
+			*field.target, err = ParseLevel(field.name)
+			if err != nil {
+				http.Error(writer, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if value.Min > value.Max {
+			http.Error(writer, fmt.Sprintf("Level min [%s] is above max [%s]", LevelToString(value.Min), LevelToString(value.Max)), http.StatusBadRequest)
+			return
+		}
+		level.SetLevel(value)
+	default:
+		writer.Header().Set("Allow", "GET, PUT")
+		http.Error(writer, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	value := level.GetLevel()
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(payload{Min: LevelToString(value.Min), Max: LevelToString(value.Max)})
+}
+
+func NewAtomicLevel(level *Level) *AtomicLevel {
+	if level == nil {
+		level = AllLevels
+	}
+
+	return &AtomicLevel{packLevel(level.Min, level.Max)}
+}
+
+func packLevel(min, max int) int64 {
+	return int64(min)<<32 | int64(uint32(max))
+}
+
+func unpackLevel(value int64) (int, int) {
+	return int(int32(value >> 32)), int(int32(value))
+}
+
+func ParseLevel(level string) (int, error) {
+	number, ok := levelsMap[strings.ToUpper(level)]
+	if !ok {
+		return DEBUG, errors.New(fmt.Sprintf("Unknown level [%s]", level))
+	}
+
+	return number, nil
+}
+
 func LevelToInt(level string) int {
 	number, ok := levelsMap[strings.ToUpper(level)]
 	if ok {
@@ -68,3 +196,15 @@ func LevelToInt(level string) int {
 
 	return DEBUG
 }
+
+func LevelNames() []string {
+	return append([]string(nil), levels...)
+}
+
+func LevelToString(level int) string {
+	if level < DEBUG || level > CRITICAL {
+		return "UNKNOWN"
+	}
+
+	return levels[level]
+}
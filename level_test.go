@@ -0,0 +1,136 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAtomicLevel(t *testing.T) {
+	level := NewAtomicLevel(&Level{INFO, ERROR})
+
+	if level.Enabled(DEBUG) || !level.Enabled(INFO) || level.Enabled(CRITICAL) {
+		t.Errorf("Unexpected enabled levels for %v", level.GetLevel())
+	}
+
+	level.SetMin(WARNING)
+	level.SetMax(CRITICAL)
+	if level.GetMin() != WARNING || level.GetMax() != CRITICAL {
+		t.Errorf("Unexpected level %v", level.GetLevel())
+	}
+
+	if value := NewAtomicLevel(nil).GetLevel(); *value != *AllLevels {
+		t.Errorf("Expected all levels, got %v", value)
+	}
+}
+
+func TestAtomicLevelServeHTTP(t *testing.T) {
+	level := NewAtomicLevel(AllLevels)
+
+	request := httptest.NewRequest("PUT", "/level", strings.NewReader(`{"level":"warning"}`))
+	response := httptest.NewRecorder()
+	level.ServeHTTP(response, request)
+
+	if response.Code != http.StatusOK || level.GetMin() != WARNING {
+		t.Errorf("Unexpected response %d %s", response.Code, response.Body.String())
+	}
+	if body := strings.TrimSpace(response.Body.String()); body != `{"min":"WARNING","max":"CRITICAL"}` {
+		t.Errorf("Unexpected body %s", body)
+	}
+
+	for _, body := range []string{`{"level":"loud"}`, `{"min":"error","max":"debug"}`, `{`} {
+		response = httptest.NewRecorder()
+		level.ServeHTTP(response, httptest.NewRequest("PUT", "/level", strings.NewReader(body)))
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("Expected a bad request for %s, got %d", body, response.Code)
+		}
+	}
+
+	response = httptest.NewRecorder()
+	level.ServeHTTP(response, httptest.NewRequest("DELETE", "/level", nil))
+	if response.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected method not allowed, got %d", response.Code)
+	}
+}
+
+func TestZeroValueLoggerLevel(t *testing.T) {
+	var logger Logger
+
+	if level := logger.GetAtomicLevel().GetLevel(); *level != *AllLevels {
+		t.Errorf("Expected all levels, got %v", level)
+	}
+
+	logger.SetLevel(ERROR)
+	if logger.GetLevel() != ERROR {
+		t.Errorf("Expected ERROR, got %d", logger.GetLevel())
+	}
+}
+
+func TestBaseHandlerSharedLevel(t *testing.T) {
+	level := NewAtomicLevel(ErrorLevels)
+	first, second := newRecordingHandler(), newRecordingHandler()
+	first.SetAtomicLevel(level)
+	second.SetAtomicLevel(level)
+
+	level.SetMin(INFO)
+	if !first.Enabled(INFO) || !second.Enabled(INFO) {
+		t.Error("Expected both handlers to follow the shared level")
+	}
+
+	second.SetLevel(&Level{CRITICAL, CRITICAL})
+	if first.Enabled(ERROR) {
+		t.Error("Expected SetLevel to update the shared level")
+	}
+
+	first.SetAtomicLevel(nil)
+	if level := first.GetLevel(); *level != *AllLevels {
+		t.Errorf("Expected all levels, got %v", level)
+	}
+}
+
+func TestZeroValueBaseHandlerConcurrentLevel(t *testing.T) {
+	var handler BaseHandler
+	var wait sync.WaitGroup
+
+	for x := 0; x < 8; x++ {
+		wait.Add(2)
+		go func() {
+			defer wait.Done()
+			handler.SetLevel(ErrorLevels)
+		}()
+		go func() {
+			defer wait.Done()
+			handler.Enabled(INFO)
+			handler.GetLevel()
+		}()
+	}
+	wait.Wait()
+
+	if level := handler.GetLevel(); *level != *ErrorLevels {
+		t.Errorf("Expected error levels, got %v", level)
+	}
+}
@@ -25,62 +25,126 @@ package golog
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
-var golog = struct {
-	sync.RWMutex
-	loggers map[string]*Logger
-}{loggers: make(map[string]*Logger)}
-
 type Logger struct {
+	stats [CRITICAL + 1][resultCount]uint64
 	sync.Mutex
-	name     string
-	level    int
-	handlers []Handler
+	registry   *Registry
+	name       string
+	level      atomic.Value
+	stackLevel int32
+	handlers   []Handler
+	sampler    *Sampler
 }
 
 func (logger *Logger) Log(level int, args ...interface{}) error {
 
-	if logger.level > level {
+	if !logger.GetAtomicLevel().Enabled(level) {
+		logger.count(level, filteredResult)
 		return nil
 	}
 
-	message := fmt.Sprint(args[0])
+	template := fmt.Sprint(args[0])
+	message := template
 
 	if len(args) != 0 {
 		message = fmt.Sprintf(message, args[1:]...)
 	}
 
 	record := NewRecord(level, logger, message)
+	record.template = template
+	if level >= logger.GetStackLevel() {
+		record.stack = callerStack(3)
+	}
+	logger.LogRecord(record)
+
+	return nil
+}
 
-	for handler := range logger.handlers {
-		handlerLevel := logger.handlers[handler].GetLevel()
-		if handlerLevel.Min <= level && level <= handlerLevel.Max {
-			logger.handlers[handler].Handle(record)
+func (logger *Logger) LogRecord(record *Record) {
+	if !logger.GetAtomicLevel().Enabled(record.level) {
+		logger.count(record.level, filteredResult)
+		return
+	}
+
+	record = redact(record, logger.registry.GetRedactors())
+
+	if sampler := logger.sampler; sampler != nil {
+		ok, summaries := sampler.Sample(record)
+		for _, summary := range summaries {
+			logger.handleSummary(summary)
+		}
+		if !ok {
+			logger.count(record.level, droppedResult)
+			return
 		}
 	}
 
-	return nil
+	logger.handle(record)
+}
+
+func (logger *Logger) handle(record *Record) {
+	emitted := false
+
+	for _, handler := range logger.handlers {
+		if !inLevel(handler, record) {
+			continue
+		}
+
+		if !emitted {
+			logger.count(record.level, emittedResult)
+			emitted = true
+		}
+
+		if tryHandler, ok := handler.(TryHandler); ok {
+			if err := tryHandler.TryHandle(record); err != nil {
+				logger.count(record.level, failedResult)
+			}
+			continue
+		}
+
+		handler.Handle(record)
+	}
+
+	if !emitted {
+		logger.count(record.level, filteredResult)
+	}
+}
+
+func (logger *Logger) handleSummary(record *Record) {
+	logger.handle(redact(record, logger.registry.GetRedactors()))
 }
 
 func (logger *Logger) SetName(name string) {
 	logger.Lock()
 	defer logger.Unlock()
 
-	golog.RLock()
-	_, ok := golog.loggers[logger.name]
-	golog.RUnlock()
-	if ok {
-		golog.Lock()
-		delete(golog.loggers, logger.name)
-		golog.Unlock()
+	registry := logger.registry
+	registry.Lock()
+	defer registry.Unlock()
+
+	if registry.loggers[logger.name] == logger {
+		delete(registry.loggers, logger.name)
 	}
 
 	logger.name = name
+	registry.loggers[logger.name] = logger
+}
 
-	golog.Lock()
-	golog.loggers[logger.name] = logger
-	golog.Unlock()
+func (logger *Logger) GetRegistry() *Registry {
+	return logger.registry
+}
+
+func (logger *Logger) reset() {
+	logger.Lock()
+	logger.handlers = []Handler{logger.registry.stdout, logger.registry.stderr}
+	logger.setSampler(nil)
+	logger.Unlock()
+
+	logger.SetAtomicLevel(NewAtomicLevel(&Level{DEBUG, CRITICAL}))
+	logger.SetStackLevel(CRITICAL + 1)
 }
 
 func (logger *Logger) GetName() string {
@@ -88,13 +152,37 @@ func (logger *Logger) GetName() string {
 }
 
 func (logger *Logger) SetLevel(level int) {
-	logger.Lock()
-	logger.level = level
-	logger.Unlock()
+	logger.GetAtomicLevel().SetMin(level)
 }
 
 func (logger *Logger) GetLevel() int {
-	return logger.level
+	return logger.GetAtomicLevel().GetMin()
+}
+
+func (logger *Logger) SetAtomicLevel(level *AtomicLevel) {
+	if level == nil {
+		level = NewAtomicLevel(&Level{DEBUG, CRITICAL})
+	}
+
+	logger.level.Store(level)
+}
+
+func (logger *Logger) GetAtomicLevel() *AtomicLevel {
+	if level, ok := logger.level.Load().(*AtomicLevel); ok {
+		return level
+	}
+
+	logger.level.CompareAndSwap(nil, NewAtomicLevel(&Level{DEBUG, CRITICAL}))
+
+	return logger.level.Load().(*AtomicLevel)
+}
+
+func (logger *Logger) SetStackLevel(level int) {
+	atomic.StoreInt32(&logger.stackLevel, int32(level))
+}
+
+func (logger *Logger) GetStackLevel() int {
+	return int(atomic.LoadInt32(&logger.stackLevel))
 }
 
 func (logger *Logger) SetHandlers(args ...Handler) {
@@ -113,6 +201,63 @@ func (logger *Logger) AddHandlers(args ...Handler) {
 	logger.Unlock()
 }
 
+func (logger *Logger) GetRecordCount() uint64 {
+	var count uint64
+	for level := range logger.stats {
+		count += atomic.LoadUint64(&logger.stats[level][emittedResult])
+	}
+
+	return count
+}
+
+func (logger *Logger) SetSampler(sampler *Sampler) {
+	logger.Lock()
+	logger.setSampler(sampler)
+	logger.Unlock()
+}
+
+func (logger *Logger) setSampler(sampler *Sampler) {
+	if logger.sampler == sampler {
+		return
+	}
+
+	if logger.sampler != nil {
+		logger.sampler.stop()
+	}
+
+	logger.sampler = sampler
+	if sampler != nil {
+		sampler.run(logger.handleSummary)
+	}
+}
+
+func (logger *Logger) GetSampler() *Sampler {
+	return logger.sampler
+}
+
+func (logger *Logger) Flush() error {
+	logger.summarize()
+
+	return flushHandlers(logger.GetHandlers())
+}
+
+func (logger *Logger) summarize() {
+	if sampler := logger.sampler; sampler != nil {
+		for _, summary := range sampler.Summary() {
+			logger.handleSummary(summary)
+		}
+	}
+}
+
+func (logger *Logger) Close() error {
+	logger.summarize()
+	if sampler := logger.GetSampler(); sampler != nil {
+		sampler.stop()
+	}
+
+	return closeHandlers(logger.GetHandlers())
+}
+
 func (logger *Logger) Debug(args ...interface{}) {
 	logger.Log(DEBUG, args...)
 }
@@ -138,22 +283,39 @@ func (logger *Logger) Critical(args ...interface{}) {
 }
 
 func GetLogger(name string) *Logger {
-	golog.RLock()
-	logger, ok := golog.loggers[name]
-	golog.RUnlock()
-	if ok {
-		return logger
-	}
+	return DefaultRegistry.GetLogger(name)
+}
+
+func Flush() error {
+	return DefaultRegistry.Flush()
+}
+
+func Close() error {
+	return DefaultRegistry.Close()
+}
 
-	logger = &Logger{
-		name:     name,
-		level:    DEBUG,
-		handlers: []Handler{StdoutHandler, StderrHandler},
+func flushHandlers(handlers []Handler) error {
+	var result error
+	for _, handler := range handlers {
+		if flusher, ok := handler.(Flusher); ok {
+			if err := flusher.Flush(); err != nil && result == nil {
+				result = err
+			}
+		}
 	}
 
-	golog.Lock()
-	golog.loggers[name] = logger
-	golog.Unlock()
+	return result
+}
+
+func closeHandlers(handlers []Handler) error {
+	var result error
+	for _, handler := range handlers {
+		if closer, ok := handler.(Closer); ok {
+			if err := closer.Close(); err != nil && result == nil {
+				result = err
+			}
+		}
+	}
 
-	return logger
+	return result
 }
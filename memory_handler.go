@@ -0,0 +1,121 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+type MemoryHandler struct {
+	BaseHandler
+	target  Handler
+	trigger int
+	records []*Record
+	start   int
+	count   int
+}
+
+func (handler *MemoryHandler) Handle(record *Record) {
+	handler.Lock()
+	defer handler.Unlock()
+
+	if len(handler.records) == 0 {
+		if record.level >= handler.trigger && handler.target != nil {
+			dispatch(handler.target, record)
+		}
+		return
+	}
+
+	index := (handler.start + handler.count) % len(handler.records)
+	handler.records[index] = record
+	if handler.count < len(handler.records) {
+		handler.count++
+	} else {
+		handler.start = (handler.start + 1) % len(handler.records)
+	}
+
+	if record.level >= handler.trigger {
+		handler.dump()
+	}
+}
+
+func (handler *MemoryHandler) Dump() {
+	handler.Lock()
+	handler.dump()
+	handler.Unlock()
+}
+
+func (handler *MemoryHandler) Close() error {
+	handler.Dump()
+	return nil
+}
+
+func (handler *MemoryHandler) dump() {
+	for x := 0; x < handler.count; x++ {
+		index := (handler.start + x) % len(handler.records)
+		record := handler.records[index]
+		handler.records[index] = nil
+
+		if handler.target != nil {
+			dispatch(handler.target, record)
+		}
+	}
+
+	handler.start, handler.count = 0, 0
+}
+
+func (handler *MemoryHandler) SetTarget(target Handler) {
+	handler.Lock()
+	handler.target = target
+	handler.Unlock()
+}
+
+func (handler *MemoryHandler) GetTarget() Handler {
+	return handler.target
+}
+
+func (handler *MemoryHandler) SetTrigger(trigger int) {
+	handler.Lock()
+	handler.trigger = trigger
+	handler.Unlock()
+}
+
+func (handler *MemoryHandler) GetTrigger() int {
+	return handler.trigger
+}
+
+func (handler *MemoryHandler) GetCapacity() int {
+	return len(handler.records)
+}
+
+func NewMemoryHandler(level *Level, formatter *Formatter, target Handler, capacity, trigger int) *MemoryHandler {
+	if capacity < 0 {
+		capacity = 0
+	}
+
+	handler := &MemoryHandler{
+		BaseHandler: BaseHandler{formatter: formatter},
+		target:      target,
+		trigger:     trigger,
+		records:     make([]*Record, capacity),
+	}
+	handler.SetAtomicLevel(NewAtomicLevel(level))
+
+	return handler
+}
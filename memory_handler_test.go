@@ -0,0 +1,68 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import "testing"
+
+func TestMemoryHandlerDumpsOnTrigger(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewMemoryHandler(AllLevels, DefaultFormatter, target, 2, ERROR)
+
+	handler.Handle(newTestRecord(INFO, "one"))
+	handler.Handle(newTestRecord(INFO, "two"))
+	handler.Handle(newTestRecord(INFO, "three"))
+
+	if messages := target.messages(); len(messages) != 0 {
+		t.Fatalf("records forwarded before trigger: %v", messages)
+	}
+
+	handler.Handle(newTestRecord(ERROR, "four"))
+
+	if messages := target.messages(); !equalStrings(messages, []string{"three", "four"}) {
+		t.Errorf("unexpected records %v", messages)
+	}
+}
+
+func TestMemoryHandlerClose(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewMemoryHandler(AllLevels, DefaultFormatter, target, 4, ERROR)
+
+	handler.Handle(newTestRecord(INFO, "one"))
+	handler.Close()
+
+	if messages := target.messages(); !equalStrings(messages, []string{"one"}) {
+		t.Errorf("unexpected records %v", messages)
+	}
+}
+
+func TestMemoryHandlerZeroCapacity(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewMemoryHandler(AllLevels, DefaultFormatter, target, -1, ERROR)
+
+	handler.Handle(newTestRecord(INFO, "info"))
+	handler.Handle(newTestRecord(CRITICAL, "critical"))
+
+	if messages := target.messages(); !equalStrings(messages, []string{"critical"}) {
+		t.Errorf("unexpected records %v", messages)
+	}
+}
@@ -0,0 +1,196 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	emittedResult = iota
+	filteredResult
+	droppedResult
+	failedResult
+	resultCount
+)
+
+var resultNames = []string{"emitted", "filtered", "dropped", "failed"}
+
+type LevelStats struct {
+	Emitted  uint64
+	Filtered uint64
+	Dropped  uint64
+	Failed   uint64
+}
+
+type LoggerStats struct {
+	Name   string
+	Levels map[string]LevelStats
+}
+
+type HandlerStats struct {
+	Name         string
+	Type         string
+	BytesWritten uint64
+}
+
+type Statistics struct {
+	Loggers  []LoggerStats
+	Handlers []HandlerStats
+}
+
+func (logger *Logger) Stats() LoggerStats {
+	stats := LoggerStats{
+		Name:   logger.GetName(),
+		Levels: make(map[string]LevelStats),
+	}
+
+	for level := range logger.stats {
+		stats.Levels[levels[level]] = LevelStats{
+			Emitted:  atomic.LoadUint64(&logger.stats[level][emittedResult]),
+			Filtered: atomic.LoadUint64(&logger.stats[level][filteredResult]),
+			Dropped:  atomic.LoadUint64(&logger.stats[level][droppedResult]),
+			Failed:   atomic.LoadUint64(&logger.stats[level][failedResult]),
+		}
+	}
+
+	return stats
+}
+
+func (logger *Logger) count(level, result int) {
+	if level >= DEBUG && level <= CRITICAL {
+		atomic.AddUint64(&logger.stats[level][result], 1)
+	}
+}
+
+func (record *Record) count(result int) {
+	if record.logger != nil {
+		record.logger.count(record.level, result)
+	}
+}
+
+func (registry *Registry) Stats() *Statistics {
+	statistics := &Statistics{}
+
+	loggers := registry.Loggers()
+	for _, logger := range loggers {
+		statistics.Loggers = append(statistics.Loggers, logger.Stats())
+	}
+
+	handlers := walkHandlers(loggers)
+	registry.forgetHandlers(handlers)
+
+	for _, handler := range handlers {
+		stats := HandlerStats{
+			Name: registry.handlerName(handler),
+			Type: handlerType(handler),
+		}
+		if writer, ok := handler.(interface{ GetBytesWritten() uint64 }); ok {
+			stats.BytesWritten = writer.GetBytesWritten()
+		}
+		statistics.Handlers = append(statistics.Handlers, stats)
+	}
+
+	return statistics
+}
+
+func (registry *Registry) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return registry.Stats()
+	}))
+}
+
+func (registry *Registry) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(writer, registry.Stats())
+	})
+}
+
+func Stats() *Statistics {
+	return DefaultRegistry.Stats()
+}
+
+func PublishExpvar(name string) {
+	DefaultRegistry.PublishExpvar(name)
+}
+
+func MetricsHandler() http.Handler {
+	return DefaultRegistry.MetricsHandler()
+}
+
+func writeMetrics(writer io.Writer, statistics *Statistics) {
+	fmt.Fprintln(writer, "# HELP golog_records_total Number of log records by logger, level and result.")
+	fmt.Fprintln(writer, "# TYPE golog_records_total counter")
+	for _, logger := range statistics.Loggers {
+		for _, level := range levels {
+			stats := logger.Levels[level]
+			for result, value := range []uint64{stats.Emitted, stats.Filtered, stats.Dropped, stats.Failed} {
+				fmt.Fprintf(writer, "golog_records_total{logger=\"%s\",level=\"%s\",result=\"%s\"} %d\n",
+					escapeLabel(logger.Name), level, resultNames[result], value)
+			}
+		}
+	}
+
+	fmt.Fprintln(writer, "# HELP golog_handler_bytes_total Number of bytes written by handler.")
+	fmt.Fprintln(writer, "# TYPE golog_handler_bytes_total counter")
+	for _, handler := range statistics.Handlers {
+		fmt.Fprintf(writer, "golog_handler_bytes_total{handler=\"%s\",type=\"%s\"} %d\n",
+			escapeLabel(handler.Name), escapeLabel(handler.Type), handler.BytesWritten)
+	}
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func walkHandlers(loggers []*Logger) []Handler {
+	var handlers []Handler
+	seen := make(map[Handler]bool)
+
+	var walk func(handler Handler)
+	walk = func(handler Handler) {
+		if handler == nil || seen[handler] {
+			return
+		}
+		seen[handler] = true
+		handlers = append(handlers, handler)
+
+		for _, child := range handlerChildren(handler) {
+			walk(child)
+		}
+	}
+
+	for _, logger := range loggers {
+		for _, handler := range logger.GetHandlers() {
+			walk(handler)
+		}
+	}
+
+	return handlers
+}
@@ -0,0 +1,143 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type erroringHandler struct {
+	BaseHandler
+}
+
+func (handler *erroringHandler) Handle(record *Record) {}
+
+func (handler *erroringHandler) TryHandle(record *Record) error {
+	return errors.New("unavailable")
+}
+
+func TestLoggerStats(t *testing.T) {
+	registry := NewRegistry()
+	target := newRecordingHandler()
+	target.SetLevel(&Level{INFO, ERROR})
+	logger := registry.GetLogger("test")
+	logger.SetHandlers(target, &erroringHandler{})
+	logger.SetLevel(INFO)
+
+	logger.Debug("filtered by logger")
+	logger.Info("emitted")
+	logger.Error("emitted")
+
+	stats := logger.Stats().Levels
+	if stats["DEBUG"].Filtered != 1 || stats["DEBUG"].Emitted != 0 {
+		t.Errorf("Unexpected DEBUG stats: %+v", stats["DEBUG"])
+	}
+	if stats["INFO"].Emitted != 1 || stats["INFO"].Failed != 1 || stats["ERROR"].Emitted != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if logger.GetRecordCount() != 2 {
+		t.Errorf("Expected 2 records, got %d", logger.GetRecordCount())
+	}
+}
+
+func TestLoggerStatsHandlerLevelCountedOnce(t *testing.T) {
+	registry := NewRegistry()
+	target := newRecordingHandler()
+	target.SetLevel(ErrorLevels)
+	logger := registry.GetLogger("test")
+	logger.SetHandlers(target)
+
+	logger.Info("rejected by handler")
+
+	stats := logger.Stats().Levels["INFO"]
+	if stats != (LevelStats{Filtered: 1}) {
+		t.Errorf("Expected the record to be counted once as filtered, got %+v", stats)
+	}
+}
+
+func TestLoggerHandlerPanicPropagates(t *testing.T) {
+	registry := NewRegistry()
+	logger := registry.GetLogger("test")
+	logger.SetHandlers(&panickingHandler{})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected the handler panic to propagate")
+		}
+	}()
+
+	logger.Info("message")
+}
+
+func TestHandlerNamesAreStable(t *testing.T) {
+	registry := NewRegistry()
+	first := newRecordingHandler()
+	registry.GetLogger("a").SetHandlers(first)
+
+	names := func() map[string]bool {
+		result := make(map[string]bool)
+		for _, handler := range registry.Stats().Handlers {
+			result[handler.Name] = true
+		}
+		return result
+	}
+
+	before := names()
+	if !before["recordinghandler1"] || !before["stdout"] || !before["stderr"] {
+		t.Fatalf("Unexpected names: %v", before)
+	}
+
+	registry.GetLogger("0").SetHandlers(newRecordingHandler())
+	after := names()
+	if !after["recordinghandler1"] || !after["recordinghandler2"] {
+		t.Errorf("Expected existing names to be kept, got %v", after)
+	}
+
+	first.SetName("primary")
+	if !names()["primary"] {
+		t.Error("Expected an explicit name to be used")
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	registry := NewRegistry()
+	logger := registry.GetLogger("test")
+	logger.SetHandlers(newRecordingHandler())
+	logger.Info("message")
+
+	response := httptest.NewRecorder()
+	registry.MetricsHandler().ServeHTTP(response, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := response.Body.String()
+	for _, line := range []string{
+		`golog_records_total{logger="test",level="INFO",result="emitted"} 1`,
+		`golog_handler_bytes_total{handler="recordinghandler1",type="recordingHandler"} 0`,
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected %s in:\n%s", line, body)
+		}
+	}
+}
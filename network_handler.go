@@ -0,0 +1,430 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultBacklogSize = 1000
+
+var networkDial = dial
+
+const (
+	NewlineFraming = iota
+	LengthPrefixFraming
+)
+
+type NetworkHandler struct {
+	BaseHandler
+	network      string
+	address      string
+	tlsConfig    *tls.Config
+	framing      int
+	writeTimeout time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	backoff      time.Duration
+	retryAt      time.Time
+	backlogSize  int
+	backlog      [][]byte
+	writing      bool
+	conn         net.Conn
+	reconnect    bool
+	attempts     uint64
+	dropped      uint64
+	err          error
+	closed       bool
+	cond         *sync.Cond
+	wake         chan struct{}
+	done         chan struct{}
+}
+
+func (handler *NetworkHandler) Handle(record *Record) {
+	handler.TryHandle(record)
+}
+
+func (handler *NetworkHandler) TryHandle(record *Record) error {
+	handler.Lock()
+	defer handler.Unlock()
+
+	if handler.closed {
+		return errors.New(fmt.Sprintf("Handler for %s [%s] is closed", handler.network, handler.address))
+	}
+
+	data := handler.frame(handler.formatter.Format(record))
+
+	if len(handler.backlog) >= handler.backlogSize && !handler.drop() {
+		handler.dropped++
+		return errors.New(fmt.Sprintf("Backlog of %s [%s] is full, record dropped", handler.network, handler.address))
+	}
+
+	handler.backlog = append(handler.backlog, data)
+	handler.notify()
+
+	return nil
+}
+
+func (handler *NetworkHandler) Flush() error {
+	handler.Lock()
+	defer handler.Unlock()
+
+	handler.notify()
+
+	attempts := handler.attempts
+	for len(handler.backlog) > 0 && !handler.closed {
+		if handler.conn == nil && time.Now().Before(handler.retryAt) {
+			return errors.New(fmt.Sprintf("Connection to %s [%s] is down, %d records pending", handler.network, handler.address, len(handler.backlog)))
+		}
+
+		if handler.err != nil && handler.attempts > attempts {
+			return handler.err
+		}
+
+		handler.cond.Wait()
+	}
+
+	return nil
+}
+
+func (handler *NetworkHandler) Close() error {
+	err := handler.Flush()
+
+	handler.Lock()
+	handler.closed = true
+	handler.notify()
+	handler.Unlock()
+
+	<-handler.done
+
+	return err
+}
+
+func (handler *NetworkHandler) drop() bool {
+	index := 0
+	if handler.writing {
+		index = 1
+	}
+
+	if index >= len(handler.backlog) {
+		return false
+	}
+
+	copy(handler.backlog[index:], handler.backlog[index+1:])
+	handler.backlog[len(handler.backlog)-1] = nil
+	handler.backlog = handler.backlog[:len(handler.backlog)-1]
+	handler.dropped++
+
+	return true
+}
+
+func (handler *NetworkHandler) notify() {
+	select {
+	case handler.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (handler *NetworkHandler) frame(formated string) []byte {
+	if handler.framing == LengthPrefixFraming {
+		formated = strings.TrimSuffix(formated, "\n")
+		data := make([]byte, 4+len(formated))
+		binary.BigEndian.PutUint32(data, uint32(len(formated)))
+		copy(data[4:], formated)
+		return data
+	}
+
+	return []byte(formated)
+}
+
+func (handler *NetworkHandler) run() {
+	defer close(handler.done)
+
+	timer := time.NewTimer(0)
+	<-timer.C
+
+	for {
+		select {
+		case <-handler.wake:
+		case <-timer.C:
+		}
+
+		wait, closed := handler.write()
+		if closed {
+			handler.Lock()
+			handler.disconnect()
+			handler.cond.Broadcast()
+			handler.Unlock()
+			return
+		}
+
+		if wait > 0 {
+			timer.Reset(wait)
+		}
+	}
+}
+
+func (handler *NetworkHandler) write() (time.Duration, bool) {
+	for {
+		handler.Lock()
+
+		if handler.reconnect {
+			handler.disconnect()
+			handler.reconnect = false
+		}
+
+		if len(handler.backlog) == 0 {
+			closed := handler.closed
+			handler.Unlock()
+			return 0, closed
+		}
+
+		if handler.conn == nil {
+			if wait := handler.retryAt.Sub(time.Now()); wait > 0 {
+				closed := handler.closed
+				handler.Unlock()
+				return wait, closed
+			}
+
+			network, address, config, timeout := handler.network, handler.address, handler.tlsConfig, handler.writeTimeout
+			handler.Unlock()
+
+			conn, err := networkDial(network, address, config, timeout)
+
+			handler.Lock()
+			if err != nil {
+				handler.fail(err)
+			} else {
+				handler.conn = conn
+				handler.backoff = 0
+			}
+			handler.Unlock()
+			continue
+		}
+
+		conn, data, timeout := handler.conn, handler.backlog[0], handler.writeTimeout
+		handler.writing = true
+		handler.Unlock()
+
+		if timeout > 0 {
+			conn.SetWriteDeadline(time.Now().Add(timeout))
+		}
+		written, err := conn.Write(data)
+
+		handler.Lock()
+		handler.writing = false
+		handler.addWritten(written)
+		if err != nil {
+			handler.disconnect()
+			handler.fail(errors.New(fmt.Sprintf("Can't write to %s [%s]: %v", handler.network, handler.address, err)))
+		} else {
+			handler.backlog[0] = nil
+			handler.backlog = handler.backlog[1:]
+			handler.err = nil
+			handler.attempts++
+			handler.cond.Broadcast()
+		}
+		handler.Unlock()
+	}
+}
+
+func (handler *NetworkHandler) fail(err error) {
+	handler.err = err
+	handler.attempts++
+	handler.retry()
+	handler.cond.Broadcast()
+}
+
+func (handler *NetworkHandler) disconnect() {
+	if handler.conn != nil {
+		handler.conn.Close()
+		handler.conn = nil
+	}
+}
+
+func (handler *NetworkHandler) retry() {
+	handler.backoff *= 2
+	if handler.backoff < handler.minBackoff {
+		handler.backoff = handler.minBackoff
+	}
+	if handler.backoff > handler.maxBackoff {
+		handler.backoff = handler.maxBackoff
+	}
+
+	handler.retryAt = time.Now().Add(handler.backoff)
+}
+
+func dial(network, address string, config *tls.Config, timeout time.Duration) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	var err error
+	if config != nil {
+		conn, err = tls.DialWithDialer(dialer, network, address, config)
+	} else {
+		conn, err = dialer.Dial(network, address)
+	}
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Can't connect to %s [%s]: %v", network, address, err))
+	}
+
+	return conn, nil
+}
+
+func isDatagram(network string) bool {
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+		return true
+	}
+
+	return false
+}
+
+func (handler *NetworkHandler) GetNetwork() string {
+	return handler.network
+}
+
+func (handler *NetworkHandler) GetAddress() string {
+	return handler.address
+}
+
+func (handler *NetworkHandler) SetTLSConfig(config *tls.Config) error {
+	if config != nil && isDatagram(handler.network) {
+		return errors.New(fmt.Sprintf("TLS is not supported over %s", handler.network))
+	}
+
+	handler.Lock()
+	handler.tlsConfig = config
+	handler.reconnect = true
+	handler.notify()
+	handler.Unlock()
+
+	return nil
+}
+
+func (handler *NetworkHandler) GetTLSConfig() *tls.Config {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.tlsConfig
+}
+
+func (handler *NetworkHandler) SetFraming(framing int) {
+	handler.Lock()
+	handler.framing = framing
+	handler.Unlock()
+}
+
+func (handler *NetworkHandler) GetFraming() int {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.framing
+}
+
+func (handler *NetworkHandler) SetWriteTimeout(timeout time.Duration) {
+	handler.Lock()
+	handler.writeTimeout = timeout
+	handler.Unlock()
+}
+
+func (handler *NetworkHandler) GetWriteTimeout() time.Duration {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.writeTimeout
+}
+
+func (handler *NetworkHandler) SetBacklogSize(size int) {
+	if size < 1 {
+		size = 1
+	}
+
+	handler.Lock()
+	handler.backlogSize = size
+	for len(handler.backlog) > size {
+		if !handler.drop() {
+			break
+		}
+	}
+	handler.Unlock()
+}
+
+func (handler *NetworkHandler) GetBacklogSize() int {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.backlogSize
+}
+
+func (handler *NetworkHandler) GetDropped() uint64 {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.dropped
+}
+
+func (handler *NetworkHandler) SetBackoff(min, max time.Duration) {
+	handler.Lock()
+	handler.minBackoff = min
+	handler.maxBackoff = max
+	handler.Unlock()
+}
+
+func NewNetworkHandler(level *Level, formatter *Formatter, network, address string) (*NetworkHandler, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", "unixgram", "unixpacket":
+	default:
+		return nil, errors.New(fmt.Sprintf("Unknown network [%s], only tcp, udp and unix are supported", network))
+	}
+
+	if len(address) <= 0 {
+		return nil, errors.New("Empty address")
+	}
+
+	handler := &NetworkHandler{
+		BaseHandler:  BaseHandler{formatter: formatter},
+		network:      network,
+		address:      address,
+		framing:      NewlineFraming,
+		writeTimeout: 5 * time.Second,
+		minBackoff:   100 * time.Millisecond,
+		maxBackoff:   30 * time.Second,
+		backlogSize:  defaultBacklogSize,
+		wake:         make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	handler.SetAtomicLevel(NewAtomicLevel(level))
+	handler.cond = sync.NewCond(&handler.Mutex)
+
+	go handler.run()
+
+	return handler, nil
+}
@@ -0,0 +1,341 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func listenTCP(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	return listener
+}
+
+func acceptLines(t *testing.T, listener net.Listener, count int) <-chan []string {
+	result := make(chan []string, 1)
+
+	go func() {
+		var lines []string
+		defer func() { result <- lines }()
+
+		for len(lines) < count {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			scanner := bufio.NewScanner(conn)
+			for len(lines) < count && scanner.Scan() {
+				lines = append(lines, scanner.Text())
+			}
+			conn.Close()
+		}
+	}()
+
+	return result
+}
+
+func newTestNetworkHandler(t *testing.T, network, address string) *NetworkHandler {
+	handler, err := NewNetworkHandler(AllLevels, NewFormatter("{message}", ""), network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.SetBackoff(time.Millisecond, 10*time.Millisecond)
+
+	return handler
+}
+
+func TestNetworkHandlerTCP(t *testing.T) {
+	listener := listenTCP(t)
+	lines := acceptLines(t, listener, 3)
+
+	handler := newTestNetworkHandler(t, "tcp", listener.Addr().String())
+	for _, message := range []string{"one", "two", "three"} {
+		handler.Handle(newTestRecord(INFO, message))
+	}
+
+	if err := handler.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case received := <-lines:
+		if !equalStrings(received, []string{"one", "two", "three"}) {
+			t.Errorf("unexpected lines %v", received)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestNetworkHandlerLengthPrefix(t *testing.T) {
+	listener := listenTCP(t)
+	received := make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header))
+		io.ReadFull(conn, data)
+		received <- string(data)
+	}()
+
+	handler := newTestNetworkHandler(t, "tcp", listener.Addr().String())
+	handler.SetFraming(LengthPrefixFraming)
+	handler.Handle(newTestRecord(INFO, "framed"))
+	handler.Close()
+
+	select {
+	case message := <-received:
+		if message != "framed" {
+			t.Errorf("unexpected message %q", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestNetworkHandlerDoesNotBlockWhenDown(t *testing.T) {
+	listener := listenTCP(t)
+	address := listener.Addr().String()
+	listener.Close()
+
+	handler := newTestNetworkHandler(t, "tcp", address)
+	handler.SetBacklogSize(3)
+
+	start := time.Now()
+	for x := 0; x < 100; x++ {
+		handler.Handle(newTestRecord(INFO, "message"))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Handle blocked for %v", elapsed)
+	}
+
+	if err := handler.Flush(); err == nil {
+		t.Error("expected flush error while collector is down")
+	}
+
+	handler.Lock()
+	pending := len(handler.backlog)
+	handler.Unlock()
+	if pending > 3 {
+		t.Errorf("backlog grew to %d", pending)
+	}
+
+	handler.Close()
+}
+
+func TestNetworkHandlerReconnects(t *testing.T) {
+	listener := listenTCP(t)
+	lines := acceptLines(t, listener, 2)
+
+	handler := newTestNetworkHandler(t, "tcp", listener.Addr().String())
+	defer handler.Close()
+
+	handler.Handle(newTestRecord(INFO, "before"))
+	if err := handler.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	handler.Lock()
+	handler.conn.Close()
+	handler.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		handler.Handle(newTestRecord(INFO, "after"))
+		if handler.Flush() == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case received := <-lines:
+		if len(received) != 2 || received[0] != "before" || received[1] != "after" {
+			t.Errorf("unexpected lines %v", received)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+type partialConn struct {
+	net.Conn
+	sync.Mutex
+	written []byte
+	fail    bool
+}
+
+func (conn *partialConn) Write(data []byte) (int, error) {
+	conn.Lock()
+	defer conn.Unlock()
+
+	if conn.fail {
+		conn.fail = false
+		half := len(data) / 2
+		conn.written = append(conn.written, data[:half]...)
+		return half, errors.New("short write")
+	}
+
+	conn.written = append(conn.written, data...)
+	return len(data), nil
+}
+
+func (conn *partialConn) SetWriteDeadline(deadline time.Time) error {
+	return nil
+}
+
+func (conn *partialConn) Close() error {
+	return nil
+}
+
+func TestNetworkHandlerResendsWholeFrame(t *testing.T) {
+	var lock sync.Mutex
+	var conns []*partialConn
+	networkDial = func(network, address string, config *tls.Config, timeout time.Duration) (net.Conn, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		conn := &partialConn{fail: len(conns) == 0}
+		conns = append(conns, conn)
+		return conn, nil
+	}
+	defer func() { networkDial = dial }()
+
+	handler := newTestNetworkHandler(t, "tcp", "collector:5140")
+	handler.Handle(newTestRecord(INFO, "first record"))
+	handler.Handle(newTestRecord(INFO, "second record"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for handler.Flush() != nil && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	handler.Close()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(conns) != 2 {
+		t.Fatalf("expected a reconnect after the short write, got %d connections", len(conns))
+	}
+
+	conns[1].Lock()
+	defer conns[1].Unlock()
+	if text := string(conns[1].written); text != "first record\nsecond record\n" {
+		t.Errorf("expected whole frames on the new connection, got %q", text)
+	}
+}
+
+func TestNetworkHandlerRejectsTLSOverUDP(t *testing.T) {
+	handler := newTestNetworkHandler(t, "udp", "127.0.0.1:5140")
+	defer handler.Close()
+
+	if err := handler.SetTLSConfig(&tls.Config{}); err == nil {
+		t.Error("expected an error for TLS over UDP")
+	}
+
+	errs := ValidateConfig(&Config{
+		Formatters: map[string]ConfigFormatter{"default": {Format: "{message}"}},
+		Handlers: map[string]ConfigHandler{"udp": {
+			Type:       "NetworkHandler",
+			Formatter:  "default",
+			Properties: map[string]interface{}{"network": "udp", "address": "127.0.0.1:5140", "tls": true},
+		}},
+		Loggers: map[string]ConfigLogger{"root": {Handlers: []string{"udp"}}},
+	})
+	if errs == nil || !strings.Contains(errs.Error(), "handlers.udp.properties.tls") {
+		t.Errorf("expected tls validation error, got %v", errs)
+	}
+}
+
+func TestNetworkHandlerClosed(t *testing.T) {
+	handler := newTestNetworkHandler(t, "udp", "127.0.0.1:5140")
+	handler.Close()
+
+	if err := handler.TryHandle(newTestRecord(INFO, "late")); err == nil {
+		t.Error("expected an error after Close")
+	}
+}
+
+func TestNetworkHandlerDropsOldestWithoutError(t *testing.T) {
+	listener := listenTCP(t)
+	address := listener.Addr().String()
+	listener.Close()
+
+	handler := newTestNetworkHandler(t, "tcp", address)
+	handler.SetBacklogSize(3)
+	defer handler.Close()
+
+	for x := 0; x < 10; x++ {
+		if err := handler.TryHandle(newTestRecord(INFO, "message")); err != nil {
+			t.Fatalf("expected the record to be accepted, got %v", err)
+		}
+	}
+
+	if dropped := handler.GetDropped(); dropped != 7 {
+		t.Errorf("expected 7 dropped records, got %d", dropped)
+	}
+}
+
+func TestNetworkHandlerFailoverDoesNotDuplicate(t *testing.T) {
+	listener := listenTCP(t)
+	address := listener.Addr().String()
+	listener.Close()
+
+	primary := newTestNetworkHandler(t, "tcp", address)
+	primary.SetBacklogSize(1)
+	defer primary.Close()
+
+	secondary := newRecordingHandler()
+	failover := NewFailoverHandler(primary, secondary)
+
+	for x := 0; x < 5; x++ {
+		failover.Handle(newTestRecord(INFO, "message"))
+	}
+
+	if messages := secondary.messages(); len(messages) != 0 {
+		t.Errorf("expected accepted records to stay with the primary, got %q", messages)
+	}
+}
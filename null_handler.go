@@ -25,8 +25,8 @@ package golog
 var NullHandler = NewNullHandler(AllLevels, DefaultFormatter)
 
 func NewNullHandler(level *Level, formatter *Formatter) Handler {
-	return &BaseHandler{
-		level:     level,
-		formatter: formatter,
-	}
+	handler := &BaseHandler{formatter: formatter}
+	handler.SetAtomicLevel(NewAtomicLevel(level))
+
+	return handler
 }
@@ -0,0 +1,323 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var parserPlaceholder = regexp.MustCompile(`\{(time|level|line|file|path|function|logger|message|stack|fields)\}`)
+
+var newlineUnescaper = strings.NewReplacer(`\\`, `\`, `\r`, "\r", `\n`, "\n")
+
+var parserPatterns = map[string]string{
+	"time":     `[^\n]+?`,
+	"level":    `(?:` + strings.Join(levels, "|") + `)`,
+	"line":     `\d+`,
+	"file":     `\S*?`,
+	"path":     `\S*?`,
+	"function": `\S*?`,
+	"logger":   `\S*?`,
+	"message":  `.*?`,
+	"stack":    `.*?`,
+	"fields":   `(?:[^\s=]+=\S*(?: [^\s=]+=\S*)*)?`,
+}
+
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", err.Line, err.Err)
+}
+
+type ParseErrors []*ParseError
+
+func (errs ParseErrors) Error() string {
+	messages := make([]string, len(errs))
+	for x, err := range errs {
+		messages[x] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+type Parser struct {
+	format     string
+	dateFormat string
+	location   *time.Location
+	multiline  int
+	indent     string
+	start      *regexp.Regexp
+	pattern    *regexp.Regexp
+	names      []string
+}
+
+func (parser *Parser) GetFormat() string {
+	return parser.format
+}
+
+func (parser *Parser) GetDateFormat() string {
+	return parser.dateFormat
+}
+
+func (parser *Parser) SetLocation(location *time.Location) {
+	parser.location = location
+}
+
+func (parser *Parser) GetLocation() *time.Location {
+	return parser.location
+}
+
+func (parser *Parser) SetMultiline(multiline int) {
+	parser.multiline = multiline
+}
+
+func (parser *Parser) GetMultiline() int {
+	return parser.multiline
+}
+
+func (parser *Parser) SetIndent(indent string) {
+	parser.indent = indent
+}
+
+func (parser *Parser) GetIndent() string {
+	return parser.indent
+}
+
+func (parser *Parser) IsRecordStart(line string) bool {
+	return parser.start.MatchString(line)
+}
+
+func (parser *Parser) IsContinuation(first, line string) bool {
+	if parser.multiline != MultilinePrefix {
+		return false
+	}
+
+	header := parser.start.FindString(first)
+
+	return len(header) > 0 && strings.HasPrefix(line, header)
+}
+
+func (parser *Parser) Parse(text string) (*Record, error) {
+	text = strings.TrimSuffix(text, "\n")
+
+	switch parser.multiline {
+	case MultilinePrefix:
+		lines := strings.Split(text, "\n")
+		header := parser.start.FindString(lines[0])
+		for x := 1; x < len(lines); x++ {
+			lines[x] = strings.TrimPrefix(lines[x], header)
+		}
+		text = strings.Join(lines, "\n")
+	case MultilineIndent:
+		if len(parser.indent) > 0 {
+			text = strings.Replace(text, "\n"+parser.indent, "\n", -1)
+		}
+	}
+
+	groups := parser.pattern.FindStringSubmatch(text)
+	if groups == nil {
+		groups = parser.pattern.FindStringSubmatch(text + "\n")
+	}
+	if groups == nil {
+		return nil, errors.New(fmt.Sprintf("Text does not match format [%s]", parser.format))
+	}
+
+	record := &Record{}
+	for x, name := range parser.names {
+		value := groups[x+1]
+
+		switch name {
+		case "time":
+			parsed, err := time.ParseInLocation(parser.dateFormat, value, parser.location)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("Can't parse time [%s]: %v", value, err))
+			}
+			record.time = parsed
+		case "level":
+			level, err := ParseLevel(value)
+			if err != nil {
+				return nil, err
+			}
+			record.level = level
+		case "line":
+			record.line, _ = strconv.Atoi(value)
+		case "file":
+			record.file = value
+		case "path":
+			record.path = value
+		case "function":
+			record.function = value
+		case "logger":
+			record.loggerName = value
+		case "message":
+			record.message = parser.unescape(value)
+		case "stack":
+			record.stack = parser.unescape(value)
+		case "fields":
+			record.fields = parseFields(value)
+		}
+	}
+
+	record.template = record.message
+
+	return record, nil
+}
+
+func (parser *Parser) ReadRecords(reader io.Reader, handle func(record *Record) error) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var errs ParseErrors
+	var pending []string
+	start, number := 0, 0
+	flush := func() error {
+		if len(pending) <= 0 {
+			return nil
+		}
+
+		record, err := parser.Parse(strings.Join(pending, "\n"))
+		pending = nil
+		if err != nil {
+			errs = append(errs, &ParseError{Line: start, Err: err})
+			return nil
+		}
+
+		return handle(record)
+	}
+
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if pending != nil && parser.IsContinuation(pending[0], line) {
+			pending = append(pending, line)
+		} else if parser.IsRecordStart(line) {
+			if err := flush(); err != nil {
+				return err
+			}
+			pending = []string{line}
+			start = number
+		} else if pending != nil {
+			pending = append(pending, line)
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (parser *Parser) unescape(value string) string {
+	if parser.multiline == MultilineEscape {
+		return newlineUnescaper.Replace(value)
+	}
+
+	return value
+}
+
+func NewParser(format, dateFormat string) (*Parser, error) {
+	format = strings.TrimSuffix(format, "\n")
+
+	parser := &Parser{
+		format:     format,
+		dateFormat: dateFormat,
+		location:   time.Local,
+		indent:     defaultIndent,
+	}
+
+	expression := ""
+	start := ""
+	last := 0
+
+	matches := parserPlaceholder.FindAllStringSubmatchIndex(format, -1)
+	for x, match := range matches {
+		expression += regexp.QuoteMeta(format[last:match[0]])
+
+		name := format[match[2]:match[3]]
+		if (name == "message" || name == "stack") && len(start) <= 0 {
+			start = expression
+		}
+
+		pattern := parserPatterns[name]
+		if x == len(matches)-1 && match[1] == len(format) && (strings.HasSuffix(pattern, "*?") || strings.HasSuffix(pattern, "+?")) {
+			pattern = pattern[:len(pattern)-1]
+		}
+
+		expression += "(" + pattern + ")"
+		parser.names = append(parser.names, name)
+		last = match[1]
+	}
+	expression += regexp.QuoteMeta(format[last:])
+
+	if len(parser.names) <= 0 {
+		return nil, errors.New(fmt.Sprintf("Format [%s] has no placeholders", format))
+	}
+
+	if len(start) <= 0 {
+		start = expression + "$"
+	}
+
+	var err error
+	if parser.start, err = regexp.Compile("^" + start); err != nil {
+		return nil, err
+	}
+
+	if parser.pattern, err = regexp.Compile("(?s)^" + expression + "$"); err != nil {
+		return nil, err
+	}
+
+	return parser, nil
+}
+
+func parseFields(text string) Fields {
+	fields := make(Fields)
+	for _, pair := range strings.Fields(text) {
+		x := strings.Index(pair, "=")
+		if x <= 0 {
+			continue
+		}
+		fields[pair[:x]] = pair[x+1:]
+	}
+
+	return fields
+}
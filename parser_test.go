@@ -0,0 +1,189 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+const parserFormat = "[{time}][{level}][{file}:{line}] {message}"
+
+func readMessages(parser *Parser, text string) ([]string, error) {
+	var messages []string
+	err := parser.ReadRecords(strings.NewReader(text), func(record *Record) error {
+		messages = append(messages, record.GetLevelName()+" "+record.GetMessage())
+		return nil
+	})
+
+	return messages, err
+}
+
+func TestParserParse(t *testing.T) {
+	parser, err := NewParser(parserFormat, "2006-01-02 15:04:05")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := parser.Parse("[2014-11-02 15:04:05][ERROR][main.go:27] Request failed")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if record.GetLevel() != ERROR || record.GetFile() != "main.go" || record.GetLine() != 27 ||
+		record.GetMessage() != "Request failed" || record.GetTime().Format("15:04:05") != "15:04:05" {
+		t.Errorf("unexpected record %+v", record)
+	}
+
+	if _, err := parser.Parse("Request failed"); err == nil {
+		t.Error("expected an error for text not matching the format")
+	}
+}
+
+func TestParserReadRecords(t *testing.T) {
+	parser, err := NewParser(parserFormat, "2006-01-02 15:04:05")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := readMessages(parser, "[2014-11-02 15:04:05][INFO][main.go:12] Started.\n"+
+		"[2014-11-02 15:04:06][ERROR][main.go:27] Request failed:\n"+
+		"connection reset by peer\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"INFO Started.", "ERROR Request failed:\nconnection reset by peer"}
+	if !equalStrings(messages, expected) {
+		t.Errorf("expected %q, got %q", expected, messages)
+	}
+}
+
+func TestParserReadRecordsReportsParseErrors(t *testing.T) {
+	parser, err := NewParser(parserFormat, "2006-01-02 15:04:05")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := readMessages(parser, "[2014-11-02 15:04:05][INFO][main.go:12] First.\n"+
+		"[yesterday][INFO][main.go:13] Bad time.\n"+
+		"continued\n"+
+		"[2014-11-02 15:04:07][INFO][main.go:14] Last.\n")
+
+	if !equalStrings(messages, []string{"INFO First.", "INFO Last."}) {
+		t.Errorf("valid records were not read: %q", messages)
+	}
+
+	errs, ok := err.(ParseErrors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("expected one parse error, got %v", err)
+	}
+	if errs[0].Line != 2 || !strings.Contains(errs[0].Error(), "line 2: Can't parse time [yesterday]") {
+		t.Errorf("unexpected error %v", errs[0])
+	}
+}
+
+func TestParserReadRecordsStopsOnHandleError(t *testing.T) {
+	parser, err := NewParser(parserFormat, "2006-01-02 15:04:05")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("stop")
+	count := 0
+	text := "[2014-11-02 15:04:05][INFO][main.go:12] First.\n[2014-11-02 15:04:06][INFO][main.go:13] Second.\n"
+	err = parser.ReadRecords(strings.NewReader(text), func(record *Record) error {
+		count++
+		return failure
+	})
+
+	if err != failure || count != 1 {
+		t.Errorf("expected to stop after the first record, got %v after %d", err, count)
+	}
+}
+
+func TestParserReadRecordsReportsReadErrors(t *testing.T) {
+	parser, err := NewParser(parserFormat, "2006-01-02 15:04:05")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("read failed")
+	if err := parser.ReadRecords(iotest.ErrReader(failure), func(record *Record) error { return nil }); err != failure {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+}
+
+func TestParserMultiline(t *testing.T) {
+	tests := []struct {
+		mode int
+		text string
+	}{
+		{MultilineEscape, "[2014-11-02 15:04:05][ERROR][main.go:1] one\\ntwo\n"},
+		{MultilinePrefix, "[2014-11-02 15:04:05][ERROR][main.go:1] one\n[2014-11-02 15:04:05][ERROR][main.go:1] two\n"},
+		{MultilineIndent, "[2014-11-02 15:04:05][ERROR][main.go:1] one\n\ttwo\n"},
+	}
+
+	for _, test := range tests {
+		parser, err := NewParser(parserFormat, "2006-01-02 15:04:05")
+		if err != nil {
+			t.Fatal(err)
+		}
+		parser.SetMultiline(test.mode)
+
+		messages, err := readMessages(parser, test.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalStrings(messages, []string{"ERROR one\ntwo"}) {
+			t.Errorf("mode %d: unexpected messages %q", test.mode, messages)
+		}
+	}
+}
+
+func TestParserEscapeRoundTrip(t *testing.T) {
+	formatter := NewFormatter(parserFormat, "2006-01-02 15:04:05")
+	formatter.SetMultiline(MultilineEscape)
+
+	parser, err := NewParser(parserFormat, "2006-01-02 15:04:05")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.SetMultiline(MultilineEscape)
+
+	for _, message := range []string{`C:\new`, `C:\\new`, "one\ntwo", `trailing\`, "literal \\n and\r\nnewline"} {
+		record := newTestRecord(ERROR, message)
+		record.time = time.Date(2014, 11, 2, 15, 4, 5, 0, time.Local)
+
+		parsed, err := parser.Parse(formatter.Format(record))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.GetMessage() != message {
+			t.Errorf("expected %q, got %q", message, parsed.GetMessage())
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"fmt"
+	"time"
+)
+
+const defaultRateLimitInterval = 10 * time.Second
+
+const (
+	RateLimitByLogger = 1 << iota
+	RateLimitByLevel
+)
+
+type rateLimitKey struct {
+	logger string
+	level  int
+}
+
+type tokenBucket struct {
+	tokens  float64
+	last    time.Time
+	dropped int
+	record  *Record
+}
+
+type RateLimitedHandler struct {
+	BaseHandler
+	handler  Handler
+	rate     float64
+	burst    int
+	keyMode  int
+	interval time.Duration
+	buckets  map[rateLimitKey]*tokenBucket
+	timer    *time.Timer
+	sweptAt  time.Time
+	closed   bool
+}
+
+func (handler *RateLimitedHandler) Handle(record *Record) {
+	handler.Lock()
+
+	key := rateLimitKey{}
+	if handler.keyMode&RateLimitByLogger != 0 {
+		key.logger = record.GetLoggerName()
+	}
+	if handler.keyMode&RateLimitByLevel != 0 {
+		key.level = record.level
+	}
+
+	now := time.Now()
+	if now.Sub(handler.sweptAt) >= handler.interval {
+		handler.sweep(now)
+	}
+
+	bucket, ok := handler.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(handler.burst), last: now}
+		handler.buckets[key] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.last).Seconds() * handler.rate
+	if bucket.tokens > float64(handler.burst) {
+		bucket.tokens = float64(handler.burst)
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		bucket.dropped++
+		bucket.record = record
+		if handler.timer == nil && !handler.closed {
+			handler.timer = time.AfterFunc(handler.interval, handler.report)
+		}
+		handler.Unlock()
+		record.count(droppedResult)
+		return
+	}
+	bucket.tokens--
+
+	dropped := bucket.dropped
+	bucket.dropped, bucket.record = 0, nil
+	handler.Unlock()
+
+	if dropped > 0 {
+		dispatch(handler.handler, droppedRecord(record, dropped))
+	}
+
+	dispatch(handler.handler, record)
+}
+
+func (handler *RateLimitedHandler) Flush() error {
+	handler.Lock()
+	if handler.timer != nil {
+		handler.timer.Stop()
+		handler.timer = nil
+	}
+	summaries := handler.summarize()
+	handler.Unlock()
+
+	for _, summary := range summaries {
+		dispatch(handler.handler, summary)
+	}
+
+	return flushHandlers([]Handler{handler.handler})
+}
+
+func (handler *RateLimitedHandler) Close() error {
+	handler.Lock()
+	handler.closed = true
+	handler.Unlock()
+
+	err := handler.Flush()
+	if closeErr := closeHandlers([]Handler{handler.handler}); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func (handler *RateLimitedHandler) report() {
+	handler.Lock()
+	handler.timer = nil
+	summaries := handler.summarize()
+	handler.sweep(time.Now())
+	handler.Unlock()
+
+	for _, summary := range summaries {
+		dispatch(handler.handler, summary)
+	}
+}
+
+func (handler *RateLimitedHandler) summarize() []*Record {
+	var summaries []*Record
+
+	for _, bucket := range handler.buckets {
+		if bucket.dropped > 0 {
+			summaries = append(summaries, droppedRecord(bucket.record, bucket.dropped))
+			bucket.dropped, bucket.record = 0, nil
+		}
+	}
+
+	return summaries
+}
+
+func (handler *RateLimitedHandler) sweep(now time.Time) {
+	for key, bucket := range handler.buckets {
+		tokens := bucket.tokens + now.Sub(bucket.last).Seconds()*handler.rate
+		if bucket.dropped == 0 && tokens >= float64(handler.burst) {
+			delete(handler.buckets, key)
+		}
+	}
+
+	handler.sweptAt = now
+}
+
+func (handler *RateLimitedHandler) GetHandler() Handler {
+	return handler.handler
+}
+
+func (handler *RateLimitedHandler) SetRate(rate float64, burst int) {
+	handler.Lock()
+	handler.rate = rate
+	handler.burst = burst
+	handler.Unlock()
+}
+
+func (handler *RateLimitedHandler) GetRate() (float64, int) {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.rate, handler.burst
+}
+
+func (handler *RateLimitedHandler) SetInterval(interval time.Duration) {
+	handler.Lock()
+	handler.interval = interval
+	handler.Unlock()
+}
+
+func (handler *RateLimitedHandler) GetInterval() time.Duration {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.interval
+}
+
+func (handler *RateLimitedHandler) SetKeyMode(mode int) {
+	handler.Lock()
+	handler.keyMode = mode
+	handler.buckets = make(map[rateLimitKey]*tokenBucket)
+	handler.Unlock()
+}
+
+func (handler *RateLimitedHandler) GetKeyMode() int {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.keyMode
+}
+
+func droppedRecord(record *Record, dropped int) *Record {
+	return MakeRecord(RecordInfo{
+		Logger:     record.logger,
+		LoggerName: record.loggerName,
+		Level:      record.level,
+		Message:    fmt.Sprintf("Rate limit exceeded, dropped %d records", dropped),
+	})
+}
+
+func NewRateLimitedHandler(handler Handler, rate float64, burst int) *RateLimitedHandler {
+	limited := &RateLimitedHandler{
+		BaseHandler: BaseHandler{formatter: handler.GetFormatter()},
+		handler:     handler,
+		rate:        rate,
+		burst:       burst,
+		interval:    defaultRateLimitInterval,
+		buckets:     make(map[rateLimitKey]*tokenBucket),
+		sweptAt:     time.Now(),
+	}
+	limited.SetAtomicLevel(NewAtomicLevel(handler.GetLevel()))
+
+	return limited
+}
@@ -0,0 +1,128 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newRateLimitRecord(logger string, level int, message string) *Record {
+	return MakeRecord(RecordInfo{LoggerName: logger, Level: level, Message: message, Path: "main.go", Line: 1})
+}
+
+func TestRateLimitedHandlerBurst(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewRateLimitedHandler(target, 0.001, 2)
+	defer handler.Close()
+
+	for x := 0; x < 5; x++ {
+		handler.Handle(newTestRecord(INFO, fmt.Sprintf("message %d", x)))
+	}
+
+	if messages := target.messages(); !equalStrings(messages, []string{"message 0", "message 1"}) {
+		t.Fatalf("Unexpected messages: %v", messages)
+	}
+
+	handler.Flush()
+	expected := []string{"message 0", "message 1", "Rate limit exceeded, dropped 3 records"}
+	if messages := target.messages(); !equalStrings(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestRateLimitedHandlerSummaryAfterSilence(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewRateLimitedHandler(target, 0.001, 1)
+	handler.SetInterval(20 * time.Millisecond)
+	defer handler.Close()
+
+	for x := 0; x < 4; x++ {
+		handler.Handle(newTestRecord(INFO, "message"))
+	}
+
+	messages := waitForMessages(target, 2)
+	if !equalStrings(messages, []string{"message", "Rate limit exceeded, dropped 3 records"}) {
+		t.Errorf("Unexpected messages: %v", messages)
+	}
+}
+
+func TestRateLimitedHandlerCloseStopsTimer(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewRateLimitedHandler(target, 0.001, 1)
+	handler.SetInterval(20 * time.Millisecond)
+
+	handler.Handle(newTestRecord(INFO, "message"))
+	handler.Handle(newTestRecord(INFO, "message"))
+	handler.Close()
+	handler.Handle(newTestRecord(INFO, "message"))
+
+	time.Sleep(60 * time.Millisecond)
+	if messages := target.messages(); len(messages) != 2 {
+		t.Errorf("Expected 2 messages, got %v", messages)
+	}
+}
+
+func TestRateLimitedHandlerEvictsIdleBuckets(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewRateLimitedHandler(target, 1000, 1)
+	handler.SetKeyMode(RateLimitByLogger)
+	handler.SetInterval(time.Millisecond)
+	defer handler.Close()
+
+	for x := 0; x < 1000; x++ {
+		handler.Handle(newRateLimitRecord(fmt.Sprintf("logger%d", x), INFO, "message"))
+		if x%100 == 99 {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	handler.Lock()
+	buckets := len(handler.buckets)
+	handler.Unlock()
+
+	if buckets >= 1000 {
+		t.Errorf("Expected idle buckets to be evicted, got %d buckets", buckets)
+	}
+	if messages := target.messages(); len(messages) != 1000 {
+		t.Errorf("Expected 1000 messages, got %d", len(messages))
+	}
+}
+
+func TestRateLimitedHandlerKeyMode(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewRateLimitedHandler(target, 0.001, 1)
+	handler.SetKeyMode(RateLimitByLogger | RateLimitByLevel)
+	defer handler.Close()
+
+	handler.Handle(newRateLimitRecord("a", INFO, "a info"))
+	handler.Handle(newRateLimitRecord("a", INFO, "a info dropped"))
+	handler.Handle(newRateLimitRecord("a", ERROR, "a error"))
+	handler.Handle(newRateLimitRecord("b", INFO, "b info"))
+
+	expected := []string{"a info", "a error", "b info"}
+	if messages := target.messages(); !equalStrings(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
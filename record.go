@@ -23,19 +23,59 @@
 package golog
 
 import (
+	"encoding/json"
+	"fmt"
 	p "path"
 	"runtime"
-	"strconv"
+	"sort"
+	"strings"
+	"time"
 )
 
+type Fields map[string]interface{}
+
+func (fields Fields) String() string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for x, key := range keys {
+		pairs[x] = fmt.Sprintf("%s=%v", key, fields[key])
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+type RecordInfo struct {
+	Logger     *Logger
+	LoggerName string
+	Level      int
+	Time       time.Time
+	Path       string
+	Line       int
+	Function   string
+	Message    string
+	Template   string
+	Stack      string
+	Fields     Fields
+}
+
 type Record struct {
-	logger   *Logger
-	level    string
-	line     string
-	file     string
-	path     string
-	function string
-	message  string
+	logger     *Logger
+	loggerName string
+	time       time.Time
+	level      int
+	line       int
+	file       string
+	path       string
+	function   string
+	message    string
+	template   string
+	stack      string
+	fields     Fields
 }
 
 func NewRecord(level int, logger *Logger, message string) *Record {
@@ -56,12 +96,224 @@ func NewRecord(level int, logger *Logger, message string) *Record {
 	}
 
 	return &Record{
-		logger,
-		levels[level],
-		strconv.Itoa(line),
-		file,
-		path,
-		function,
-		message,
+		logger:   logger,
+		time:     time.Now(),
+		level:    level,
+		line:     line,
+		file:     file,
+		path:     path,
+		function: function,
+		message:  message,
+		template: message,
+	}
+}
+
+func MakeRecord(info RecordInfo) *Record {
+	record := &Record{
+		logger:     info.Logger,
+		loggerName: info.LoggerName,
+		time:       info.Time,
+		level:      info.Level,
+		line:       info.Line,
+		path:       info.Path,
+		function:   info.Function,
+		message:    info.Message,
+		template:   info.Template,
+		stack:      info.Stack,
+	}
+
+	if record.time.IsZero() {
+		record.time = time.Now()
+	}
+
+	if len(record.template) <= 0 {
+		record.template = record.message
+	}
+
+	if len(record.path) > 0 {
+		record.file = p.Base(record.path)
+	}
+
+	if len(info.Fields) > 0 {
+		record.fields = make(Fields, len(info.Fields))
+		for key, value := range info.Fields {
+			record.fields[key] = value
+		}
 	}
+
+	return record
+}
+
+func (record *Record) Clone() *Record {
+	clone := *record
+
+	if record.fields != nil {
+		clone.fields = make(Fields, len(record.fields))
+		for key, value := range record.fields {
+			clone.fields[key] = value
+		}
+	}
+
+	return &clone
+}
+
+func (record *Record) GetLogger() *Logger {
+	return record.logger
+}
+
+func (record *Record) GetLoggerName() string {
+	if record.logger == nil {
+		return record.loggerName
+	}
+
+	return record.logger.name
+}
+
+func (record *Record) GetTime() time.Time {
+	return record.time
+}
+
+func (record *Record) GetLevel() int {
+	return record.level
+}
+
+func (record *Record) GetLevelName() string {
+	return LevelToString(record.level)
+}
+
+func (record *Record) GetLine() int {
+	return record.line
+}
+
+func (record *Record) GetFile() string {
+	return record.file
+}
+
+func (record *Record) GetPath() string {
+	return record.path
+}
+
+func (record *Record) GetFunction() string {
+	return record.function
+}
+
+func (record *Record) GetMessage() string {
+	return record.message
+}
+
+func (record *Record) GetTemplate() string {
+	return record.template
+}
+
+func (record *Record) GetStack() string {
+	return record.stack
+}
+
+func (record *Record) GetField(key string) (interface{}, bool) {
+	value, ok := record.fields[key]
+	return value, ok
+}
+
+func (record *Record) GetFields() Fields {
+	fields := make(Fields, len(record.fields))
+	for key, value := range record.fields {
+		fields[key] = value
+	}
+
+	return fields
+}
+
+func (record *Record) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Time     string `json:"time"`
+		Logger   string `json:"logger"`
+		Level    string `json:"level"`
+		File     string `json:"file,omitempty"`
+		Line     int    `json:"line,omitempty"`
+		Path     string `json:"path,omitempty"`
+		Function string `json:"function,omitempty"`
+		Message  string `json:"message"`
+		Stack    string `json:"stack,omitempty"`
+		Fields   Fields `json:"fields,omitempty"`
+	}{
+		record.time.Format(time.RFC3339Nano),
+		record.GetLoggerName(),
+		LevelToString(record.level),
+		record.file,
+		record.line,
+		record.path,
+		record.function,
+		record.message,
+		record.stack,
+		record.fields,
+	})
+}
+
+func (record *Record) UnmarshalJSON(data []byte) error {
+	var value struct {
+		Time     string `json:"time"`
+		Logger   string `json:"logger"`
+		Level    string `json:"level"`
+		File     string `json:"file"`
+		Line     int    `json:"line"`
+		Path     string `json:"path"`
+		Function string `json:"function"`
+		Message  string `json:"message"`
+		Stack    string `json:"stack"`
+		Fields   Fields `json:"fields"`
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	level, err := ParseLevel(value.Level)
+	if err != nil {
+		return err
+	}
+
+	var parsed time.Time
+	if len(value.Time) > 0 {
+		parsed, err = time.Parse(time.RFC3339Nano, value.Time)
+		if err != nil {
+			return err
+		}
+	}
+
+	*record = Record{
+		loggerName: value.Logger,
+		time:       parsed,
+		level:      level,
+		line:       value.Line,
+		file:       value.File,
+		path:       value.Path,
+		function:   value.Function,
+		message:    value.Message,
+		template:   value.Message,
+		stack:      value.Stack,
+		fields:     value.Fields,
+	}
+
+	if len(record.file) <= 0 && len(record.path) > 0 {
+		record.file = p.Base(record.path)
+	}
+
+	return nil
+}
+
+func callerStack(skip int) string {
+	pcs := make([]uintptr, 32)
+	count := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:count])
+
+	var lines []string
+	for {
+		frame, more := frames.Next()
+		lines = append(lines, fmt.Sprintf("%s\n\t%s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+
+	return strings.Join(lines, "\n")
 }
@@ -0,0 +1,124 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMakeRecord(t *testing.T) {
+	now := time.Date(2014, 11, 2, 15, 4, 5, 0, time.UTC)
+	record := MakeRecord(RecordInfo{
+		Level:    WARNING,
+		Time:     now,
+		Path:     "/src/app/main.go",
+		Line:     42,
+		Function: "main.main",
+		Message:  "disk 90% full",
+		Template: "disk %d%% full",
+		Fields:   Fields{"disk": "sda"},
+	})
+
+	if record.GetLevel() != WARNING || record.GetLevelName() != "WARNING" {
+		t.Errorf("unexpected level %d", record.GetLevel())
+	}
+	if !record.GetTime().Equal(now) {
+		t.Errorf("unexpected time %v", record.GetTime())
+	}
+	if record.GetFile() != "main.go" || record.GetPath() != "/src/app/main.go" || record.GetLine() != 42 {
+		t.Errorf("unexpected caller %s %s:%d", record.GetPath(), record.GetFile(), record.GetLine())
+	}
+	if record.GetMessage() != "disk 90% full" || record.GetTemplate() != "disk %d%% full" {
+		t.Errorf("unexpected message %q %q", record.GetMessage(), record.GetTemplate())
+	}
+	if value, ok := record.GetField("disk"); !ok || value != "sda" {
+		t.Errorf("unexpected field %v", value)
+	}
+}
+
+func TestMakeRecordDefaults(t *testing.T) {
+	record := MakeRecord(RecordInfo{Message: "hello"})
+
+	if record.GetTime().IsZero() {
+		t.Error("time not set")
+	}
+	if record.GetTemplate() != "hello" {
+		t.Errorf("unexpected template %q", record.GetTemplate())
+	}
+}
+
+func TestRecordFieldsAreCopied(t *testing.T) {
+	fields := Fields{"user": "alice"}
+	record := MakeRecord(RecordInfo{Message: "hello", Fields: fields})
+	fields["user"] = "bob"
+
+	clone := record.Clone()
+	clone.fields["user"] = "carol"
+
+	returned := record.GetFields()
+	returned["user"] = "dave"
+
+	if value, _ := record.GetField("user"); value != "alice" {
+		t.Errorf("record fields were shared, got %v", value)
+	}
+}
+
+func TestFieldsString(t *testing.T) {
+	fields := Fields{"b": 2, "a": "x"}
+
+	if text := fields.String(); text != "a=x b=2" {
+		t.Errorf("unexpected fields %q", text)
+	}
+}
+
+func TestRecordJSONRoundTrip(t *testing.T) {
+	record := MakeRecord(RecordInfo{
+		LoggerName: "app.db",
+		Level:      ERROR,
+		Time:       time.Date(2014, 11, 2, 15, 4, 5, 123, time.UTC),
+		Path:       "/src/app/db.go",
+		Line:       7,
+		Message:    "query failed",
+		Fields:     Fields{"table": "users"},
+	})
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := &Record{}
+	if err := json.Unmarshal(data, parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.GetLoggerName() != "app.db" || parsed.GetLevel() != ERROR || parsed.GetFile() != "db.go" ||
+		parsed.GetLine() != 7 || parsed.GetMessage() != "query failed" || !parsed.GetTime().Equal(record.GetTime()) {
+		t.Errorf("unexpected record %s", data)
+	}
+	if value, _ := parsed.GetField("table"); value != "users" {
+		t.Errorf("unexpected field %v", value)
+	}
+}
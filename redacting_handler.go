@@ -0,0 +1,66 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+type RedactingHandler struct {
+	BaseHandler
+	handler   Handler
+	redactors []Redactor
+}
+
+func (handler *RedactingHandler) Handle(record *Record) {
+	dispatch(handler.handler, redact(record, handler.redactors))
+}
+
+func (handler *RedactingHandler) Flush() error {
+	return flushHandlers([]Handler{handler.handler})
+}
+
+func (handler *RedactingHandler) Close() error {
+	return closeHandlers([]Handler{handler.handler})
+}
+
+func (handler *RedactingHandler) GetHandler() Handler {
+	return handler.handler
+}
+
+func (handler *RedactingHandler) SetRedactors(redactors ...Redactor) {
+	handler.Lock()
+	handler.redactors = redactors
+	handler.Unlock()
+}
+
+func (handler *RedactingHandler) GetRedactors() []Redactor {
+	return handler.redactors
+}
+
+func NewRedactingHandler(handler Handler, redactors ...Redactor) *RedactingHandler {
+	redacting := &RedactingHandler{
+		BaseHandler: BaseHandler{formatter: handler.GetFormatter()},
+		handler:     handler,
+		redactors:   redactors,
+	}
+	redacting.SetAtomicLevel(NewAtomicLevel(handler.GetLevel()))
+
+	return redacting
+}
@@ -0,0 +1,276 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var RedactedMask = "******"
+
+var CreditCardRedactor = mustRegexRedactor(`\b(?:\d[ -]?){12,18}\d\b`, "[CREDIT CARD]").withValidator(luhnValid)
+var BearerTokenRedactor = mustRegexRedactor(`(?i)\b(bearer)\s+[A-Za-z0-9\-._~+/]+=*`, "$1 "+RedactedMask)
+var EmailRedactor = mustRegexRedactor(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`, "[EMAIL]")
+
+var builtinRedactors = map[string]Redactor{
+	"creditCard":  CreditCardRedactor,
+	"bearerToken": BearerTokenRedactor,
+	"email":       EmailRedactor,
+}
+
+type Redactor interface {
+	Redact(record *Record) *Record
+}
+
+type RedactorFunc func(record *Record) *Record
+
+func (redactor RedactorFunc) Redact(record *Record) *Record {
+	return redactor(record)
+}
+
+type Sensitive struct {
+	Value interface{}
+}
+
+func (sensitive Sensitive) String() string {
+	return RedactedMask
+}
+
+func (sensitive Sensitive) Format(state fmt.State, verb rune) {
+	io.WriteString(state, RedactedMask)
+}
+
+func (sensitive Sensitive) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + RedactedMask + `"`), nil
+}
+
+type RegexRedactor struct {
+	pattern     *regexp.Regexp
+	replacement string
+	validate    func(match string) bool
+}
+
+func (redactor *RegexRedactor) Redact(record *Record) *Record {
+	message := redactor.replace(record.message)
+	template := redactor.replace(record.template)
+
+	var fields Fields
+	for key, value := range record.fields {
+		text, ok := redactableText(value)
+		if !ok {
+			continue
+		}
+		if redacted := redactor.replace(text); redacted != text {
+			if fields == nil {
+				fields = make(Fields)
+			}
+			fields[key] = redacted
+		}
+	}
+
+	if message == record.message && template == record.template && fields == nil {
+		return record
+	}
+
+	clone := record.Clone()
+	clone.message = message
+	clone.template = template
+	for key, value := range fields {
+		clone.fields[key] = value
+	}
+
+	return clone
+}
+
+func (redactor *RegexRedactor) replace(text string) string {
+	if redactor.validate == nil {
+		return redactor.pattern.ReplaceAllString(text, redactor.replacement)
+	}
+
+	var result []byte
+	last := 0
+	for _, match := range redactor.pattern.FindAllStringSubmatchIndex(text, -1) {
+		if !redactor.validate(text[match[0]:match[1]]) {
+			continue
+		}
+		result = append(result, text[last:match[0]]...)
+		result = redactor.pattern.ExpandString(result, redactor.replacement, text, match)
+		last = match[1]
+	}
+
+	if result == nil {
+		return text
+	}
+
+	return string(append(result, text[last:]...))
+}
+
+func (redactor *RegexRedactor) withValidator(validate func(match string) bool) *RegexRedactor {
+	redactor.validate = validate
+	return redactor
+}
+
+func (redactor *RegexRedactor) GetPattern() string {
+	return redactor.pattern.String()
+}
+
+func (redactor *RegexRedactor) GetReplacement() string {
+	return redactor.replacement
+}
+
+func NewRegexRedactor(pattern, replacement string) (*RegexRedactor, error) {
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Can't compile redaction pattern [%s]: %v", pattern, err))
+	}
+
+	return &RegexRedactor{pattern: compiled, replacement: replacement}, nil
+}
+
+func mustRegexRedactor(pattern, replacement string) *RegexRedactor {
+	redactor, err := NewRegexRedactor(pattern, replacement)
+	if err != nil {
+		panic(err)
+	}
+
+	return redactor
+}
+
+type FieldRedactor struct {
+	names   map[string]bool
+	pattern *regexp.Regexp
+}
+
+func (redactor *FieldRedactor) Redact(record *Record) *Record {
+	message, template := record.message, record.template
+	if redactor.pattern != nil {
+		message = redactor.pattern.ReplaceAllString(message, "${1}${2}"+RedactedMask)
+		template = redactor.pattern.ReplaceAllString(template, "${1}${2}"+RedactedMask)
+	}
+
+	var masked []string
+	for key := range record.fields {
+		if redactor.names[strings.ToLower(key)] {
+			masked = append(masked, key)
+		}
+	}
+
+	if message == record.message && template == record.template && len(masked) == 0 {
+		return record
+	}
+
+	clone := record.Clone()
+	clone.message = message
+	clone.template = template
+	for _, key := range masked {
+		clone.fields[key] = RedactedMask
+	}
+
+	return clone
+}
+
+func (redactor *FieldRedactor) GetNames() []string {
+	names := make([]string, 0, len(redactor.names))
+	for name := range redactor.names {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func NewFieldRedactor(names ...string) *FieldRedactor {
+	redactor := &FieldRedactor{names: make(map[string]bool)}
+
+	var quoted []string
+	for _, name := range names {
+		redactor.names[strings.ToLower(name)] = true
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+
+	if len(quoted) > 0 {
+		redactor.pattern = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)("?\s*[=:]\s*"?)[^\s",&;]+`)
+	}
+
+	return redactor
+}
+
+func redactableText(value interface{}) (string, bool) {
+	switch value.(type) {
+	case string:
+		return value.(string), true
+	case fmt.Stringer, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(value), true
+	}
+
+	return "", false
+}
+
+func luhnValid(number string) bool {
+	sum, digits := 0, 0
+	for x := len(number) - 1; x >= 0; x-- {
+		if number[x] < '0' || number[x] > '9' {
+			continue
+		}
+
+		digit := int(number[x] - '0')
+		if digits%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		digits++
+	}
+
+	return digits > 0 && sum%10 == 0
+}
+
+func GetBuiltinRedactor(name string) (Redactor, bool) {
+	redactor, ok := builtinRedactors[name]
+	return redactor, ok
+}
+
+func SetRedactors(redactors ...Redactor) {
+	DefaultRegistry.SetRedactors(redactors...)
+}
+
+func GetRedactors() []Redactor {
+	return DefaultRegistry.GetRedactors()
+}
+
+func AddRedactors(redactors ...Redactor) {
+	DefaultRegistry.AddRedactors(redactors...)
+}
+
+func redact(record *Record, redactors []Redactor) *Record {
+	for _, redactor := range redactors {
+		record = redactor.Redact(record)
+	}
+
+	return record
+}
@@ -0,0 +1,195 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type cardNumber string
+
+func (number cardNumber) String() string {
+	return string(number)
+}
+
+func TestLuhnValid(t *testing.T) {
+	cases := map[string]bool{
+		"4111111111111111":    true,
+		"4111 1111 1111 1111": true,
+		"5500-0000-0000-0004": true,
+		"4111111111111112":    false,
+		"1234567890123456":    false,
+		"":                    false,
+	}
+
+	for number, expected := range cases {
+		if valid := luhnValid(number); valid != expected {
+			t.Errorf("luhnValid(%q) = %v, expected %v", number, valid, expected)
+		}
+	}
+}
+
+func TestCreditCardRedactorMessage(t *testing.T) {
+	record := newTestRecord(INFO, "card 4111 1111 1111 1111, order 1234567890123456")
+	redacted := CreditCardRedactor.Redact(record)
+
+	expected := "card [CREDIT CARD], order 1234567890123456"
+	if redacted.GetMessage() != expected {
+		t.Errorf("Expected %q, got %q", expected, redacted.GetMessage())
+	}
+	if record.GetMessage() == expected {
+		t.Error("Redaction must not modify the original record")
+	}
+}
+
+func TestCreditCardRedactorFields(t *testing.T) {
+	record := MakeRecord(RecordInfo{
+		Level:   INFO,
+		Message: "payment",
+		Fields: Fields{
+			"text":     "4111111111111111",
+			"number":   uint64(4111111111111111),
+			"stringer": cardNumber("5500000000000004"),
+			"order":    int64(1234567890123456),
+			"amount":   42,
+		},
+	})
+	redacted := CreditCardRedactor.Redact(record)
+
+	fields := redacted.GetFields()
+	for _, key := range []string{"text", "number", "stringer"} {
+		if fields[key] != "[CREDIT CARD]" {
+			t.Errorf("Expected field %s to be redacted, got %v", key, fields[key])
+		}
+	}
+	if fields["order"] != int64(1234567890123456) || fields["amount"] != 42 {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+}
+
+func TestBearerTokenRedactor(t *testing.T) {
+	redacted := BearerTokenRedactor.Redact(newTestRecord(INFO, "Authorization: Bearer abc.def-123"))
+
+	expected := "Authorization: Bearer " + RedactedMask
+	if redacted.GetMessage() != expected {
+		t.Errorf("Expected %q, got %q", expected, redacted.GetMessage())
+	}
+}
+
+func TestEmailRedactorUnchanged(t *testing.T) {
+	record := newTestRecord(INFO, "nothing to hide")
+	if EmailRedactor.Redact(record) != record {
+		t.Error("Expected the same record when nothing is redacted")
+	}
+}
+
+func TestFieldRedactor(t *testing.T) {
+	redactor := NewFieldRedactor("password", "Token")
+	record := MakeRecord(RecordInfo{
+		Level:   INFO,
+		Message: `login password=secret token: "abc" user=bob`,
+		Fields:  Fields{"Password": "secret", "user": "bob"},
+	})
+	redacted := redactor.Redact(record)
+
+	expected := `login password=` + RedactedMask + ` token: "` + RedactedMask + `" user=bob`
+	if redacted.GetMessage() != expected {
+		t.Errorf("Expected %q, got %q", expected, redacted.GetMessage())
+	}
+	if fields := redacted.GetFields(); fields["Password"] != RedactedMask || fields["user"] != "bob" {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+}
+
+func TestSensitive(t *testing.T) {
+	value := Sensitive{"secret"}
+
+	if text := fmt.Sprintf("%v %s %d", value, value, value); text != RedactedMask+" "+RedactedMask+" "+RedactedMask {
+		t.Errorf("Unexpected formatting: %q", text)
+	}
+	if data, _ := value.MarshalJSON(); string(data) != `"`+RedactedMask+`"` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+}
+
+func TestLoggerRedactors(t *testing.T) {
+	registry := NewRegistry()
+	target := newRecordingHandler()
+	logger := registry.GetLogger("test")
+	logger.SetHandlers(target)
+	registry.SetRedactors(EmailRedactor, CreditCardRedactor)
+
+	logger.Info("mail %s card %s", "bob@example.com", "4111111111111111")
+
+	expected := []string{"mail [EMAIL] card [CREDIT CARD]"}
+	if messages := target.messages(); !equalStrings(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestRedactorsRedactTemplate(t *testing.T) {
+	record := MakeRecord(RecordInfo{Level: INFO, Message: "auth Bearer abc123 password=hunter2"})
+	record.template = record.message
+
+	for _, redactor := range []Redactor{BearerTokenRedactor, NewFieldRedactor("password")} {
+		redacted := redactor.Redact(record)
+		if redacted.GetTemplate() != redacted.GetMessage() {
+			t.Errorf("%T: expected the template %q to match the message %q", redactor, redacted.GetTemplate(), redacted.GetMessage())
+		}
+		if record.GetTemplate() != "auth Bearer abc123 password=hunter2" {
+			t.Errorf("%T: the original record was changed", redactor)
+		}
+	}
+}
+
+func TestLoggerRedactsSamplerSummaries(t *testing.T) {
+	registry := NewRegistry()
+	target := newRecordingHandler()
+	logger := registry.GetLogger("sampled")
+	logger.SetHandlers(target)
+	registry.SetRedactors(BearerTokenRedactor, RedactorFunc(func(record *Record) *Record {
+		clone := record.Clone()
+		clone.message = strings.Replace(clone.message, "hunter2", RedactedMask, -1)
+		return clone
+	}))
+	logger.SetSampler(NewSampler(time.Hour, 1, 0))
+	defer logger.SetSampler(nil)
+
+	for x := 0; x < 3; x++ {
+		logger.Info("auth Bearer abc123")
+		logger.Info("key hunter2")
+	}
+	logger.Flush()
+
+	for _, message := range target.messages() {
+		if strings.Contains(message, "abc123") || strings.Contains(message, "hunter2") {
+			t.Errorf("secret leaked in %q", message)
+		}
+	}
+	if messages := target.messages(); len(messages) != 4 {
+		t.Errorf("expected two records and two summaries, got %q", messages)
+	}
+}
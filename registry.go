@@ -0,0 +1,329 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"os"
+	"sort"
+	"sync"
+)
+
+var DefaultRegistry = newRegistry(DefaultFormatter, StdoutHandler, StderrHandler, NullHandler)
+
+type Registry struct {
+	sync.RWMutex
+	loggers   map[string]*Logger
+	root      *Logger
+	formatter *Formatter
+	stdout    Handler
+	stderr    Handler
+	null      Handler
+	redactors []Redactor
+
+	handlerTypes  map[string]*handlerTypeInfo
+	handlerNames  map[Handler]string
+	handlerCounts map[string]int
+}
+
+type loggerSnapshot struct {
+	logger     *Logger
+	name       string
+	level      *AtomicLevel
+	value      *Level
+	stackLevel int
+	handlers   []Handler
+	sampler    *Sampler
+}
+
+type formatterSnapshot struct {
+	format     string
+	dateFormat string
+	multiline  int
+	indent     string
+}
+
+type RegistrySnapshot struct {
+	loggers    []loggerSnapshot
+	levels     map[Handler]*Level
+	formatters map[*Formatter]formatterSnapshot
+	redactors  []Redactor
+}
+
+func (registry *Registry) GetLogger(name string) *Logger {
+	registry.RLock()
+	logger, ok := registry.loggers[name]
+	registry.RUnlock()
+	if ok {
+		return logger
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	logger, ok = registry.loggers[name]
+	if ok {
+		return logger
+	}
+
+	logger = &Logger{registry: registry, name: name}
+	logger.reset()
+	registry.loggers[name] = logger
+
+	return logger
+}
+
+func (registry *Registry) GetRoot() *Logger {
+	return registry.root
+}
+
+func (registry *Registry) GetFormatter() *Formatter {
+	return registry.formatter
+}
+
+func (registry *Registry) GetStdoutHandler() Handler {
+	return registry.stdout
+}
+
+func (registry *Registry) GetStderrHandler() Handler {
+	return registry.stderr
+}
+
+func (registry *Registry) GetNullHandler() Handler {
+	return registry.null
+}
+
+func (registry *Registry) Loggers() []*Logger {
+	registry.RLock()
+	loggers := make([]*Logger, 0, len(registry.loggers))
+	for _, logger := range registry.loggers {
+		loggers = append(loggers, logger)
+	}
+	registry.RUnlock()
+
+	sort.Slice(loggers, func(x, y int) bool {
+		return loggers[x].GetName() < loggers[y].GetName()
+	})
+
+	return loggers
+}
+
+func (registry *Registry) RemoveLogger(name string) bool {
+	registry.Lock()
+	defer registry.Unlock()
+
+	logger, ok := registry.loggers[name]
+	if !ok || logger == registry.root {
+		return false
+	}
+
+	delete(registry.loggers, name)
+
+	return true
+}
+
+func (registry *Registry) ResetAll() {
+	for _, logger := range registry.Loggers() {
+		logger.reset()
+	}
+
+	registry.stdout.SetLevel(InfoLevels)
+	registry.stderr.SetLevel(ErrorLevels)
+	registry.null.SetLevel(AllLevels)
+	registry.formatter.SetFormat(defaultFormat)
+	registry.formatter.SetDateFormat(defaultDateFormat)
+	registry.formatter.SetMultiline(MultilineKeep)
+	registry.formatter.SetIndent(defaultIndent)
+	registry.SetRedactors()
+}
+
+func (registry *Registry) Snapshot() *RegistrySnapshot {
+	snapshot := &RegistrySnapshot{
+		levels:     make(map[Handler]*Level),
+		formatters: make(map[*Formatter]formatterSnapshot),
+		redactors:  registry.GetRedactors(),
+	}
+
+	loggers := registry.Loggers()
+	for _, logger := range loggers {
+		logger.Lock()
+		state := loggerSnapshot{
+			logger:   logger,
+			name:     logger.name,
+			handlers: append([]Handler(nil), logger.handlers...),
+			sampler:  logger.sampler,
+		}
+		logger.Unlock()
+
+		state.level = logger.GetAtomicLevel()
+		state.value = state.level.GetLevel()
+		state.stackLevel = logger.GetStackLevel()
+		snapshot.loggers = append(snapshot.loggers, state)
+	}
+
+	formatters := []*Formatter{registry.formatter}
+	for _, handler := range walkHandlers(loggers) {
+		snapshot.levels[handler] = handler.GetLevel()
+		if formatter := handler.GetFormatter(); formatter != nil {
+			formatters = append(formatters, formatter)
+		}
+	}
+
+	for _, formatter := range formatters {
+		snapshot.formatters[formatter] = formatterSnapshot{
+			format:     formatter.GetFormat(),
+			dateFormat: formatter.GetDateFormat(),
+			multiline:  formatter.GetMultiline(),
+			indent:     formatter.GetIndent(),
+		}
+	}
+
+	return snapshot
+}
+
+func (registry *Registry) Restore(snapshot *RegistrySnapshot) {
+	registry.Lock()
+	registry.loggers = make(map[string]*Logger)
+	for _, state := range snapshot.loggers {
+		registry.loggers[state.name] = state.logger
+	}
+	registry.Unlock()
+
+	for _, state := range snapshot.loggers {
+		state.logger.Lock()
+		state.logger.name = state.name
+		state.logger.handlers = append([]Handler(nil), state.handlers...)
+		state.logger.setSampler(state.sampler)
+		state.logger.Unlock()
+
+		state.level.SetLevel(state.value)
+		state.logger.SetAtomicLevel(state.level)
+		state.logger.SetStackLevel(state.stackLevel)
+	}
+
+	for handler, level := range snapshot.levels {
+		handler.SetLevel(level)
+	}
+
+	for formatter, state := range snapshot.formatters {
+		formatter.SetFormat(state.format)
+		formatter.SetDateFormat(state.dateFormat)
+		formatter.SetMultiline(state.multiline)
+		formatter.SetIndent(state.indent)
+	}
+
+	registry.SetRedactors(snapshot.redactors...)
+}
+
+func (registry *Registry) Flush() error {
+	loggers := registry.Loggers()
+	for _, logger := range loggers {
+		logger.summarize()
+	}
+
+	return flushHandlers(topHandlers(loggers))
+}
+
+func (registry *Registry) Close() error {
+	return closeHandlers(topHandlers(registry.Loggers()))
+}
+
+func (registry *Registry) SetRedactors(redactors ...Redactor) {
+	registry.Lock()
+	registry.redactors = redactors
+	registry.Unlock()
+}
+
+func (registry *Registry) GetRedactors() []Redactor {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return registry.redactors
+}
+
+func (registry *Registry) AddRedactors(redactors ...Redactor) {
+	registry.Lock()
+	registry.redactors = append(registry.redactors, redactors...)
+	registry.Unlock()
+}
+
+func NewRegistry() *Registry {
+	formatter := NewFormatter(defaultFormat, defaultDateFormat)
+
+	return newRegistry(
+		formatter,
+		NewStreamHandler(InfoLevels, formatter, os.Stdout),
+		NewStreamHandler(ErrorLevels, formatter, os.Stderr),
+		NewNullHandler(AllLevels, formatter),
+	)
+}
+
+func newRegistry(formatter *Formatter, stdout, stderr, null Handler) *Registry {
+	registry := &Registry{
+		loggers:       make(map[string]*Logger),
+		formatter:     formatter,
+		stdout:        stdout,
+		stderr:        stderr,
+		null:          null,
+		handlerNames:  make(map[Handler]string),
+		handlerCounts: make(map[string]int),
+	}
+	registry.handlerTypes = registry.defaultHandlerTypes()
+	registry.root = registry.GetLogger("root")
+
+	return registry
+}
+
+func topHandlers(loggers []*Logger) []Handler {
+	var handlers []Handler
+	seen := make(map[Handler]bool)
+	for _, logger := range loggers {
+		for _, handler := range logger.GetHandlers() {
+			if !seen[handler] {
+				seen[handler] = true
+				handlers = append(handlers, handler)
+			}
+		}
+	}
+
+	return handlers
+}
+
+func Loggers() []*Logger {
+	return DefaultRegistry.Loggers()
+}
+
+func RemoveLogger(name string) bool {
+	return DefaultRegistry.RemoveLogger(name)
+}
+
+func ResetAll() {
+	DefaultRegistry.ResetAll()
+}
+
+func Snapshot() *RegistrySnapshot {
+	return DefaultRegistry.Snapshot()
+}
+
+func Restore(snapshot *RegistrySnapshot) {
+	DefaultRegistry.Restore(snapshot)
+}
@@ -0,0 +1,234 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestGetLoggerConcurrent(t *testing.T) {
+	registry := NewRegistry()
+	loggers := make([]*Logger, 16)
+
+	var wait sync.WaitGroup
+	for x := range loggers {
+		wait.Add(1)
+		go func(x int) {
+			defer wait.Done()
+			loggers[x] = registry.GetLogger("shared")
+		}(x)
+	}
+	wait.Wait()
+
+	for _, logger := range loggers {
+		if logger != loggers[0] {
+			t.Fatal("Expected a single logger for the same name")
+		}
+	}
+}
+
+func TestLoggersSorted(t *testing.T) {
+	registry := NewRegistry()
+	registry.GetLogger("b")
+	registry.GetLogger("a")
+
+	var names []string
+	for _, logger := range registry.Loggers() {
+		names = append(names, logger.GetName())
+	}
+
+	if !equalStrings(names, []string{"a", "b", "root"}) {
+		t.Errorf("Unexpected loggers: %v", names)
+	}
+}
+
+func TestRemoveLogger(t *testing.T) {
+	registry := NewRegistry()
+	logger := registry.GetLogger("test")
+
+	if !registry.RemoveLogger("test") || registry.RemoveLogger("test") {
+		t.Error("Expected the logger to be removed once")
+	}
+	if registry.GetLogger("test") == logger {
+		t.Error("Expected a new logger after removal")
+	}
+
+	root := registry.GetRoot()
+	if registry.RemoveLogger("root") {
+		t.Error("Expected the root logger removal to be rejected")
+	}
+	if registry.GetRoot() != root {
+		t.Error("Expected the root logger to be kept")
+	}
+
+	root.SetName("main")
+	if registry.RemoveLogger("main") {
+		t.Error("Expected the renamed root logger removal to be rejected")
+	}
+	if registry.GetRoot() != root || registry.GetLogger("main") != root {
+		t.Error("Expected the renamed root logger to be kept")
+	}
+}
+
+func TestResetAll(t *testing.T) {
+	registry := NewRegistry()
+	logger := registry.GetLogger("test")
+	logger.SetLevel(ERROR)
+	logger.SetHandlers(newRecordingHandler())
+	registry.GetStdoutHandler().SetLevel(ErrorLevels)
+	registry.GetFormatter().SetFormat("{message}")
+	registry.GetFormatter().SetMultiline(MultilineIndent)
+	registry.GetFormatter().SetIndent("  ")
+	registry.SetRedactors(EmailRedactor)
+
+	registry.ResetAll()
+
+	if logger.GetLevel() != DEBUG || len(logger.GetHandlers()) != 2 {
+		t.Errorf("Expected a default logger, got level %d and %d handlers", logger.GetLevel(), len(logger.GetHandlers()))
+	}
+	if level := registry.GetStdoutHandler().GetLevel(); *level != *InfoLevels {
+		t.Errorf("Expected info levels, got %v", level)
+	}
+	if registry.GetFormatter().GetFormat() != defaultFormat || len(registry.GetRedactors()) != 0 {
+		t.Error("Expected the default formatter and no redactors")
+	}
+	if formatter := registry.GetFormatter(); formatter.GetMultiline() != MultilineKeep || formatter.GetIndent() != defaultIndent {
+		t.Errorf("Expected the default multiline mode and indent, got %d and %q", formatter.GetMultiline(), formatter.GetIndent())
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	registry := NewRegistry()
+	target := newRecordingHandler()
+	logger := registry.GetLogger("test")
+	logger.SetHandlers(target)
+	logger.SetLevel(WARNING)
+	logger.SetStackLevel(ERROR)
+	target.SetLevel(ErrorLevels)
+
+	snapshot := registry.Snapshot()
+
+	logger.SetLevel(DEBUG)
+	logger.SetStackLevel(DEBUG)
+	logger.SetHandlers()
+	target.SetLevel(AllLevels)
+	registry.GetFormatter().SetFormat("{message}")
+	registry.GetFormatter().SetMultiline(MultilinePrefix)
+	registry.GetFormatter().SetIndent("  ")
+	registry.SetRedactors(EmailRedactor)
+	registry.GetLogger("extra")
+
+	registry.Restore(snapshot)
+
+	if registry.GetLogger("test") != logger || logger.GetLevel() != WARNING {
+		t.Errorf("Expected the logger to be restored, got level %d", logger.GetLevel())
+	}
+	if logger.GetStackLevel() != ERROR {
+		t.Errorf("Expected the stack level to be restored, got %d", logger.GetStackLevel())
+	}
+	if handlers := logger.GetHandlers(); len(handlers) != 1 || handlers[0] != target {
+		t.Errorf("Unexpected handlers: %v", handlers)
+	}
+	if level := target.GetLevel(); *level != *ErrorLevels {
+		t.Errorf("Expected error levels, got %v", level)
+	}
+	if registry.GetFormatter().GetFormat() != defaultFormat || len(registry.GetRedactors()) != 0 {
+		t.Error("Expected the formatter and redactors to be restored")
+	}
+	if formatter := registry.GetFormatter(); formatter.GetMultiline() != MultilineKeep || formatter.GetIndent() != defaultIndent {
+		t.Error("Expected the multiline mode and indent to be restored")
+	}
+	for _, logger := range registry.Loggers() {
+		if logger.GetName() == "extra" {
+			t.Error("Expected loggers created after the snapshot to be removed")
+		}
+	}
+}
+
+func TestRegistriesAreIndependent(t *testing.T) {
+	first, second := NewRegistry(), NewRegistry()
+
+	first.GetLogger("app").SetLevel(ERROR)
+	first.GetFormatter().SetFormat("{message}")
+
+	if second.GetLogger("app").GetLevel() != DEBUG {
+		t.Error("Expected the logger level to be isolated")
+	}
+	if second.GetFormatter().GetFormat() != defaultFormat || DefaultFormatter.GetFormat() != defaultFormat {
+		t.Error("Expected the formatter to be isolated")
+	}
+	if first.GetStdoutHandler() == second.GetStdoutHandler() || first.GetStdoutHandler() == StdoutHandler {
+		t.Error("Expected separate default handlers")
+	}
+	if DefaultRegistry.GetStdoutHandler() != StdoutHandler || DefaultRegistry.GetFormatter() != DefaultFormatter {
+		t.Error("Expected the default registry to own the package defaults")
+	}
+}
+
+func TestRegistryApplyConfig(t *testing.T) {
+	first, second := NewRegistry(), NewRegistry()
+
+	err := first.ApplyConfig(&Config{
+		Handlers: map[string]ConfigHandler{
+			"stdout": {Type: "StdoutHandler"},
+		},
+		Loggers: map[string]ConfigLogger{
+			"app": {Level: "error", Handlers: []string{"stdout"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handlers := first.GetLogger("app").GetHandlers()
+	if len(handlers) != 1 || handlers[0] != first.GetStdoutHandler() {
+		t.Errorf("Expected the registry stdout handler, got %v", handlers)
+	}
+	if first.GetLogger("app").GetLevel() != ERROR || second.GetLogger("app").GetLevel() != DEBUG {
+		t.Error("Expected the config to apply to a single registry")
+	}
+}
+
+func TestRegistryLoadConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "golog.json")
+	data := `{"loggers": {"root": {"level": "warning", "handlers": ["null"]}}, "handlers": {"null": {"type": "NullHandler"}}}`
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewRegistry()
+	if err := registry.LoadConfig(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	root := registry.GetRoot()
+	if root.GetLevel() != WARNING || len(root.GetHandlers()) != 1 || root.GetHandlers()[0] != registry.GetNullHandler() {
+		t.Errorf("Unexpected root logger: level %d, handlers %v", root.GetLevel(), root.GetHandlers())
+	}
+	if DefaultRegistry.GetRoot().GetLevel() == WARNING {
+		t.Error("Expected the default registry to be untouched")
+	}
+}
@@ -0,0 +1,189 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type samplerKey struct {
+	level    int
+	template string
+}
+
+type samplerCount struct {
+	logger  *Logger
+	count   int
+	dropped int
+}
+
+type Sampler struct {
+	sync.Mutex
+	interval   time.Duration
+	first      int
+	thereafter int
+	start      time.Time
+	counts     map[samplerKey]*samplerCount
+	emit       func(record *Record)
+	done       chan struct{}
+}
+
+func (sampler *Sampler) Sample(record *Record) (bool, []*Record) {
+	sampler.Lock()
+	defer sampler.Unlock()
+
+	var summaries []*Record
+
+	now := time.Now()
+	if now.Sub(sampler.start) >= sampler.interval {
+		summaries = sampler.summarize()
+		sampler.start = now
+	}
+
+	key := samplerKey{record.level, record.template}
+	count, ok := sampler.counts[key]
+	if !ok {
+		count = &samplerCount{}
+		sampler.counts[key] = count
+	}
+	count.logger = record.logger
+	count.count++
+
+	if count.count <= sampler.first {
+		return true, summaries
+	}
+
+	if sampler.thereafter > 0 && (count.count-sampler.first)%sampler.thereafter == 0 {
+		return true, summaries
+	}
+
+	count.dropped++
+
+	return false, summaries
+}
+
+func (sampler *Sampler) Summary() []*Record {
+	sampler.Lock()
+	defer sampler.Unlock()
+
+	summaries := sampler.summarize()
+	sampler.start = time.Now()
+
+	return summaries
+}
+
+func (sampler *Sampler) run(emit func(record *Record)) {
+	sampler.Lock()
+	defer sampler.Unlock()
+
+	sampler.emit = emit
+	if sampler.done != nil || sampler.interval <= 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	sampler.done = done
+
+	go func() {
+		ticker := time.NewTicker(sampler.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				summaries, emit := sampler.due()
+				for _, summary := range summaries {
+					emit(summary)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+func (sampler *Sampler) stop() {
+	sampler.Lock()
+	if sampler.done != nil {
+		close(sampler.done)
+		sampler.done = nil
+	}
+	sampler.emit = nil
+	sampler.Unlock()
+}
+
+func (sampler *Sampler) due() ([]*Record, func(record *Record)) {
+	sampler.Lock()
+	defer sampler.Unlock()
+
+	now := time.Now()
+	if sampler.emit == nil || now.Sub(sampler.start) < sampler.interval {
+		return nil, nil
+	}
+	sampler.start = now
+
+	return sampler.summarize(), sampler.emit
+}
+
+func (sampler *Sampler) summarize() []*Record {
+	var summaries []*Record
+
+	for key, count := range sampler.counts {
+		if count.dropped > 0 {
+			summaries = append(summaries, MakeRecord(RecordInfo{
+				Logger:   count.logger,
+				Level:    key.level,
+				Message:  fmt.Sprintf("Sampled out %d messages: %s", count.dropped, key.template),
+				Template: key.template,
+			}))
+		}
+	}
+
+	sampler.counts = make(map[samplerKey]*samplerCount)
+
+	return summaries
+}
+
+func (sampler *Sampler) GetInterval() time.Duration {
+	return sampler.interval
+}
+
+func (sampler *Sampler) GetFirst() int {
+	return sampler.first
+}
+
+func (sampler *Sampler) GetThereafter() int {
+	return sampler.thereafter
+}
+
+func NewSampler(interval time.Duration, first, thereafter int) *Sampler {
+	return &Sampler{
+		interval:   interval,
+		first:      first,
+		thereafter: thereafter,
+		start:      time.Now(),
+		counts:     make(map[samplerKey]*samplerCount),
+	}
+}
@@ -0,0 +1,130 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func waitForMessages(handler *recordingHandler, count int) []string {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if messages := handler.messages(); len(messages) >= count {
+			return messages
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return handler.messages()
+}
+
+func TestSamplerFirstThereafter(t *testing.T) {
+	sampler := NewSampler(time.Hour, 2, 3)
+
+	var kept []int
+	for x := 1; x <= 10; x++ {
+		if ok, _ := sampler.Sample(newTestRecord(INFO, "message")); ok {
+			kept = append(kept, x)
+		}
+	}
+
+	if len(kept) != 4 || kept[0] != 1 || kept[1] != 2 || kept[2] != 5 || kept[3] != 8 {
+		t.Errorf("unexpected sampled records %v", kept)
+	}
+
+	summaries := sampler.Summary()
+	if len(summaries) != 1 || summaries[0].GetMessage() != "Sampled out 6 messages: message" {
+		t.Errorf("unexpected summaries %v", summaries)
+	}
+}
+
+func TestSamplerKeysByLevelAndTemplate(t *testing.T) {
+	sampler := NewSampler(time.Hour, 1, 0)
+
+	for _, record := range []*Record{newTestRecord(INFO, "a"), newTestRecord(ERROR, "a"), newTestRecord(INFO, "b")} {
+		if ok, _ := sampler.Sample(record); !ok {
+			t.Errorf("first %s record %q was dropped", record.GetLevelName(), record.GetMessage())
+		}
+	}
+}
+
+func TestSamplingHandlerSummaryWithoutTraffic(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewSamplingHandler(target, NewSampler(20*time.Millisecond, 1, 0))
+	defer handler.Close()
+
+	for x := 0; x < 5; x++ {
+		handler.Handle(newTestRecord(INFO, "burst"))
+	}
+
+	messages := waitForMessages(target, 2)
+	if len(messages) != 2 || messages[1] != "Sampled out 4 messages: burst" {
+		t.Errorf("unexpected records %v", messages)
+	}
+}
+
+func TestSamplingHandlerCloseStopsTicker(t *testing.T) {
+	target := newRecordingHandler()
+	sampler := NewSampler(10*time.Millisecond, 0, 0)
+	handler := NewSamplingHandler(target, sampler)
+
+	handler.Handle(newTestRecord(INFO, "dropped"))
+	handler.Close()
+
+	sampler.Lock()
+	running := sampler.done != nil
+	sampler.Unlock()
+	if running {
+		t.Error("ticker still running after Close")
+	}
+
+	if messages := target.messages(); len(messages) != 1 || !strings.HasPrefix(messages[0], "Sampled out 1") {
+		t.Errorf("unexpected records %v", messages)
+	}
+
+	handler.Handle(newTestRecord(INFO, "dropped"))
+	time.Sleep(50 * time.Millisecond)
+	if messages := target.messages(); len(messages) != 1 {
+		t.Errorf("summary emitted after Close: %v", messages)
+	}
+}
+
+func TestLoggerSamplerSummaryWithoutTraffic(t *testing.T) {
+	registry := NewRegistry()
+	target := newRecordingHandler()
+	logger := registry.GetLogger("sampled")
+	logger.SetHandlers(target)
+	logger.SetSampler(NewSampler(20*time.Millisecond, 1, 0))
+	defer logger.SetSampler(nil)
+
+	for x := 0; x < 3; x++ {
+		logger.Info("burst")
+	}
+
+	messages := waitForMessages(target, 2)
+	if len(messages) != 2 || messages[1] != "Sampled out 2 messages: burst" {
+		t.Errorf("unexpected records %v", messages)
+	}
+}
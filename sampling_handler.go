@@ -0,0 +1,84 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+type SamplingHandler struct {
+	BaseHandler
+	handler Handler
+	sampler *Sampler
+}
+
+func (handler *SamplingHandler) Handle(record *Record) {
+	ok, summaries := handler.sampler.Sample(record)
+	for _, summary := range summaries {
+		dispatch(handler.handler, summary)
+	}
+
+	if ok {
+		dispatch(handler.handler, record)
+	} else {
+		record.count(droppedResult)
+	}
+}
+
+func (handler *SamplingHandler) Flush() error {
+	for _, summary := range handler.sampler.Summary() {
+		dispatch(handler.handler, summary)
+	}
+
+	return flushHandlers([]Handler{handler.handler})
+}
+
+func (handler *SamplingHandler) Close() error {
+	handler.sampler.stop()
+
+	err := handler.Flush()
+	if closeErr := closeHandlers([]Handler{handler.handler}); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func (handler *SamplingHandler) GetHandler() Handler {
+	return handler.handler
+}
+
+func (handler *SamplingHandler) GetSampler() *Sampler {
+	return handler.sampler
+}
+
+func NewSamplingHandler(handler Handler, sampler *Sampler) *SamplingHandler {
+	sampling := &SamplingHandler{
+		BaseHandler: BaseHandler{formatter: handler.GetFormatter()},
+		handler:     handler,
+		sampler:     sampler,
+	}
+	sampling.SetAtomicLevel(NewAtomicLevel(handler.GetLevel()))
+
+	sampler.run(func(summary *Record) {
+		dispatch(handler, summary)
+	})
+
+	return sampling
+}
@@ -22,31 +22,170 @@
 
 package golog
 
-import "os"
+import (
+	"bufio"
+	"os"
+	"time"
+)
 
 var StdoutHandler = NewStreamHandler(InfoLevels, DefaultFormatter, os.Stdout)
 var StderrHandler = NewStreamHandler(ErrorLevels, DefaultFormatter, os.Stderr)
 
 type StreamHandler struct {
 	BaseHandler
-	stream *os.File
+	stream        *os.File
+	buffer        *bufio.Writer
+	flushInterval time.Duration
+	flushLevel    int
+	timer         *time.Timer
 }
 
 func (handler *StreamHandler) Handle(record *Record) {
+	handler.TryHandle(record)
+}
+
+func (handler *StreamHandler) TryHandle(record *Record) error {
 	handler.Lock()
 	defer handler.Unlock()
 
+	return handler.write(record)
+}
+
+func (handler *StreamHandler) write(record *Record) error {
 	formated := handler.formatter.Format(record)
 
-	handler.stream.WriteString(formated)
+	if handler.buffer == nil {
+		written, err := handler.stream.WriteString(formated)
+		handler.addWritten(written)
+
+		return err
+	}
+
+	written, err := handler.buffer.WriteString(formated)
+	handler.addWritten(written)
+	if err != nil {
+		return err
+	}
+
+	if record.level >= handler.flushLevel {
+		return handler.flush()
+	}
+
+	if handler.flushInterval > 0 && handler.timer == nil && handler.buffer.Buffered() > 0 {
+		handler.timer = time.AfterFunc(handler.flushInterval, func() {
+			handler.Lock()
+			handler.flush()
+			handler.Unlock()
+		})
+	}
+
+	return nil
+}
+
+func (handler *StreamHandler) SetBufferSize(size int) error {
+	handler.Lock()
+	defer handler.Unlock()
+
+	err := handler.flush()
+
+	if size > 0 {
+		handler.buffer = bufio.NewWriterSize(handler.stream, size)
+	} else {
+		handler.buffer = nil
+	}
+
+	return err
+}
+
+func (handler *StreamHandler) GetBufferSize() int {
+	handler.Lock()
+	defer handler.Unlock()
+
+	if handler.buffer == nil {
+		return 0
+	}
+
+	return handler.buffer.Size()
+}
+
+func (handler *StreamHandler) SetFlushInterval(interval time.Duration) {
+	handler.Lock()
+	handler.flushInterval = interval
+	handler.Unlock()
+}
+
+func (handler *StreamHandler) GetFlushInterval() time.Duration {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.flushInterval
+}
+
+func (handler *StreamHandler) SetFlushLevel(level int) {
+	handler.Lock()
+	handler.flushLevel = level
+	handler.Unlock()
+}
+
+func (handler *StreamHandler) GetFlushLevel() int {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.flushLevel
+}
+
+func (handler *StreamHandler) Flush() error {
+	handler.Lock()
+	defer handler.Unlock()
+
+	return handler.flush()
+}
+
+func (handler *StreamHandler) flush() error {
+	if handler.timer != nil {
+		handler.timer.Stop()
+		handler.timer = nil
+	}
+
+	if handler.buffer == nil {
+		return nil
+	}
+
+	return handler.buffer.Flush()
+}
+
+func (handler *StreamHandler) Close() error {
+	handler.Lock()
+	defer handler.Unlock()
+
+	err := handler.flush()
+
+	if handler.stream == os.Stdout || handler.stream == os.Stderr {
+		return err
+	}
+
+	if closeErr := handler.stream.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
 }
 
 func NewStreamHandler(level *Level, formatter *Formatter, stream *os.File) Handler {
-	return &StreamHandler{
-		BaseHandler: BaseHandler{
-			level:     level,
-			formatter: formatter,
-		},
-		stream: stream,
+	handler := &StreamHandler{
+		BaseHandler: BaseHandler{formatter: formatter},
+		stream:      stream,
+		flushLevel:  ERROR,
 	}
+	handler.SetAtomicLevel(NewAtomicLevel(level))
+
+	return handler
+}
+
+func NewBufferedStreamHandler(level *Level, formatter *Formatter, stream *os.File, size int, interval time.Duration) Handler {
+	handler := NewStreamHandler(level, formatter, stream).(*StreamHandler)
+	handler.SetBufferSize(size)
+	handler.SetFlushInterval(interval)
+
+	return handler
 }
@@ -0,0 +1,188 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func createStream(t testing.TB) (*os.File, string) {
+	filename := filepath.Join(t.TempDir(), "stream.log")
+	stream, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { stream.Close() })
+
+	return stream, filename
+}
+
+func readStream(t *testing.T, filename string) string {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestStreamHandlerUnbuffered(t *testing.T) {
+	stream, filename := createStream(t)
+	handler := NewStreamHandler(AllLevels, NewFormatter("{message}", ""), stream)
+
+	handler.Handle(newTestRecord(INFO, "written"))
+
+	if text := readStream(t, filename); text != "written\n" {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestStreamHandlerBuffered(t *testing.T) {
+	stream, filename := createStream(t)
+	handler := NewBufferedStreamHandler(AllLevels, NewFormatter("{message}", ""), stream, 1024, 0).(*StreamHandler)
+
+	handler.Handle(newTestRecord(INFO, "buffered"))
+	if text := readStream(t, filename); text != "" {
+		t.Errorf("expected the record to be buffered, got %q", text)
+	}
+
+	if err := handler.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if text := readStream(t, filename); text != "buffered\n" {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestStreamHandlerFlushLevel(t *testing.T) {
+	stream, filename := createStream(t)
+	handler := NewBufferedStreamHandler(AllLevels, NewFormatter("{message}", ""), stream, 1024, 0).(*StreamHandler)
+
+	handler.Handle(newTestRecord(INFO, "info"))
+	handler.Handle(newTestRecord(ERROR, "error"))
+	if text := readStream(t, filename); text != "info\nerror\n" {
+		t.Errorf("expected an error record to flush the buffer, got %q", text)
+	}
+
+	handler.SetFlushLevel(CRITICAL)
+	handler.Handle(newTestRecord(ERROR, "delayed"))
+	if text := readStream(t, filename); text != "info\nerror\n" {
+		t.Errorf("expected the record to be buffered below the flush level, got %q", text)
+	}
+}
+
+func TestStreamHandlerFlushInterval(t *testing.T) {
+	stream, filename := createStream(t)
+	handler := NewBufferedStreamHandler(AllLevels, NewFormatter("{message}", ""), stream, 1024, 10*time.Millisecond)
+
+	handler.Handle(newTestRecord(INFO, "later"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for readStream(t, filename) != "later\n" {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the buffer to be flushed after the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStreamHandlerSetBufferSize(t *testing.T) {
+	stream, filename := createStream(t)
+	handler := NewBufferedStreamHandler(AllLevels, NewFormatter("{message}", ""), stream, 1024, time.Second).(*StreamHandler)
+
+	if handler.GetBufferSize() != 1024 || handler.GetFlushInterval() != time.Second || handler.GetFlushLevel() != ERROR {
+		t.Errorf("unexpected settings %d, %v, %d", handler.GetBufferSize(), handler.GetFlushInterval(), handler.GetFlushLevel())
+	}
+
+	handler.Handle(newTestRecord(INFO, "pending"))
+	if err := handler.SetBufferSize(0); err != nil {
+		t.Fatal(err)
+	}
+	if handler.GetBufferSize() != 0 || readStream(t, filename) != "pending\n" {
+		t.Error("expected disabling the buffer to flush it")
+	}
+}
+
+func TestStreamHandlerCloseFlushes(t *testing.T) {
+	stream, filename := createStream(t)
+	handler := NewBufferedStreamHandler(AllLevels, NewFormatter("{message}", ""), stream, 1024, time.Hour).(*StreamHandler)
+
+	handler.Handle(newTestRecord(INFO, "closing"))
+	if err := handler.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if text := readStream(t, filename); text != "closing\n" {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestStreamHandlerConcurrentSettings(t *testing.T) {
+	stream, _ := createStream(t)
+	handler := NewBufferedStreamHandler(AllLevels, NewFormatter("{message}", ""), stream, 1024, time.Millisecond).(*StreamHandler)
+	defer handler.Close()
+
+	var group sync.WaitGroup
+	group.Add(2)
+	go func() {
+		defer group.Done()
+		for x := 0; x < 100; x++ {
+			handler.SetBufferSize(512 + x)
+			handler.SetFlushInterval(time.Duration(x) * time.Millisecond)
+			handler.SetFlushLevel(WARNING)
+		}
+	}()
+	go func() {
+		defer group.Done()
+		for x := 0; x < 100; x++ {
+			handler.Handle(newTestRecord(INFO, "message"))
+			handler.GetBufferSize()
+			handler.GetFlushInterval()
+			handler.GetFlushLevel()
+		}
+	}()
+	group.Wait()
+}
+
+func benchmarkStreamHandler(b *testing.B, handler *StreamHandler) {
+	record := newTestRecord(INFO, "benchmark message")
+
+	b.ResetTimer()
+	for x := 0; x < b.N; x++ {
+		handler.Handle(record)
+	}
+	handler.Flush()
+}
+
+func BenchmarkStreamHandlerUnbuffered(b *testing.B) {
+	stream, _ := createStream(b)
+	benchmarkStreamHandler(b, NewStreamHandler(AllLevels, DefaultFormatter, stream).(*StreamHandler))
+}
+
+func BenchmarkStreamHandlerBuffered(b *testing.B) {
+	stream, _ := createStream(b)
+	benchmarkStreamHandler(b, NewBufferedStreamHandler(AllLevels, DefaultFormatter, stream, 64*1024, time.Second).(*StreamHandler))
+}
@@ -0,0 +1,307 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type TeeOptions struct {
+	Timeout   time.Duration
+	Async     bool
+	QueueSize int
+}
+
+type teeBranch struct {
+	sync.Mutex
+	handler Handler
+	options TeeOptions
+	queue   []*Record
+	pending int
+	stuck   int
+	closed  bool
+	cond    *sync.Cond
+	done    chan struct{}
+	dropped uint64
+	failed  uint64
+}
+
+type TeeHandler struct {
+	BaseHandler
+	branches []*teeBranch
+	onError  func(handler Handler, record *Record, err error)
+}
+
+func (handler *TeeHandler) Handle(record *Record) {
+	handler.Lock()
+	branches := handler.branches
+	handler.Unlock()
+
+	for _, branch := range branches {
+		if !inLevel(branch.handler, record) {
+			continue
+		}
+
+		if branch.options.Async {
+			branch.enqueue(record)
+			continue
+		}
+
+		handler.handle(branch, record)
+	}
+}
+
+func (branch *teeBranch) enqueue(record *Record) {
+	branch.Lock()
+	defer branch.Unlock()
+
+	if branch.closed || len(branch.queue) >= branch.options.QueueSize {
+		atomic.AddUint64(&branch.dropped, 1)
+		record.count(droppedResult)
+		return
+	}
+
+	branch.queue = append(branch.queue, record)
+	branch.pending++
+	branch.cond.Broadcast()
+}
+
+func (handler *TeeHandler) handle(branch *teeBranch, record *Record) {
+	var err error
+
+	if branch.options.Timeout > 0 {
+		err = handler.handleTimeout(branch, record)
+	} else {
+		err = tryHandle(branch.handler, record)
+	}
+
+	if err != nil {
+		atomic.AddUint64(&branch.failed, 1)
+		record.count(failedResult)
+
+		handler.Lock()
+		onError := handler.onError
+		handler.Unlock()
+
+		if onError != nil {
+			onError(branch.handler, record, err)
+		}
+	}
+}
+
+func (handler *TeeHandler) handleTimeout(branch *teeBranch, record *Record) error {
+	branch.Lock()
+	if branch.stuck > 0 {
+		branch.Unlock()
+		atomic.AddUint64(&branch.dropped, 1)
+		record.count(droppedResult)
+		return nil
+	}
+	branch.Unlock()
+
+	var finished, timedOut bool
+	result := make(chan error, 1)
+	go func() {
+		err := tryHandle(branch.handler, record)
+
+		branch.Lock()
+		finished = true
+		if timedOut {
+			branch.stuck--
+		}
+		branch.Unlock()
+
+		result <- err
+	}()
+
+	timer := time.NewTimer(branch.options.Timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+	}
+
+	branch.Lock()
+	if !finished {
+		timedOut = true
+		branch.stuck++
+	}
+	branch.Unlock()
+
+	return errors.New(fmt.Sprintf("Handler %T timed out after %v", branch.handler, branch.options.Timeout))
+}
+
+func (handler *TeeHandler) run(branch *teeBranch) {
+	defer close(branch.done)
+
+	branch.Lock()
+	defer branch.Unlock()
+
+	for {
+		for len(branch.queue) == 0 && !branch.closed {
+			branch.cond.Wait()
+		}
+		if len(branch.queue) == 0 {
+			return
+		}
+
+		record := branch.queue[0]
+		branch.queue[0] = nil
+		branch.queue = branch.queue[1:]
+		branch.Unlock()
+
+		handler.handle(branch, record)
+
+		branch.Lock()
+		branch.pending--
+		branch.cond.Broadcast()
+	}
+}
+
+func (handler *TeeHandler) AddHandler(branchHandler Handler, options TeeOptions) {
+	if options.Async && options.QueueSize <= 0 {
+		options.QueueSize = 1024
+	}
+
+	branch := &teeBranch{
+		handler: branchHandler,
+		options: options,
+	}
+	branch.cond = sync.NewCond(&branch.Mutex)
+
+	if options.Async {
+		branch.done = make(chan struct{})
+		go handler.run(branch)
+	}
+
+	handler.Lock()
+	handler.branches = append(handler.branches, branch)
+	handler.Unlock()
+}
+
+func (handler *TeeHandler) GetHandlers() []Handler {
+	handler.Lock()
+	defer handler.Unlock()
+
+	handlers := make([]Handler, len(handler.branches))
+	for x, branch := range handler.branches {
+		handlers[x] = branch.handler
+	}
+
+	return handlers
+}
+
+func (handler *TeeHandler) GetDropped() uint64 {
+	var dropped uint64
+
+	handler.Lock()
+	branches := handler.branches
+	handler.Unlock()
+
+	for _, branch := range branches {
+		dropped += atomic.LoadUint64(&branch.dropped)
+	}
+
+	return dropped
+}
+
+func (handler *TeeHandler) GetFailed() uint64 {
+	var failed uint64
+
+	handler.Lock()
+	branches := handler.branches
+	handler.Unlock()
+
+	for _, branch := range branches {
+		failed += atomic.LoadUint64(&branch.failed)
+	}
+
+	return failed
+}
+
+func (handler *TeeHandler) SetOnError(onError func(handler Handler, record *Record, err error)) {
+	handler.Lock()
+	handler.onError = onError
+	handler.Unlock()
+}
+
+func (handler *TeeHandler) Flush() error {
+	handler.Lock()
+	branches := handler.branches
+	handler.Unlock()
+
+	for _, branch := range branches {
+		branch.Lock()
+		for branch.pending > 0 {
+			branch.cond.Wait()
+		}
+		branch.Unlock()
+	}
+
+	return flushHandlers(handler.GetHandlers())
+}
+
+func (handler *TeeHandler) Close() error {
+	err := handler.Flush()
+
+	handler.Lock()
+	branches := handler.branches
+	handler.branches = nil
+	handler.Unlock()
+
+	for _, branch := range branches {
+		branch.Lock()
+		branch.closed = true
+		branch.cond.Broadcast()
+		branch.Unlock()
+
+		if branch.done != nil {
+			<-branch.done
+		}
+
+		if closeErr := closeHandlers([]Handler{branch.handler}); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+func NewTeeHandler(handlers ...Handler) *TeeHandler {
+	handler := &TeeHandler{
+		BaseHandler: BaseHandler{formatter: DefaultFormatter},
+	}
+	handler.SetAtomicLevel(NewAtomicLevel(AllLevels))
+
+	for _, branch := range handlers {
+		handler.AddHandler(branch, TeeOptions{})
+	}
+
+	return handler
+}
@@ -0,0 +1,295 @@
+// golog - Logging library for Go
+//
+// Copyright (c) 2014 Dmitry Prazdnichnov <dp@bambucha.org>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type blockingHandler struct {
+	recordingHandler
+	release chan struct{}
+}
+
+func (handler *blockingHandler) Handle(record *Record) {
+	<-handler.release
+	handler.recordingHandler.Handle(record)
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{*newRecordingHandler(), make(chan struct{})}
+}
+
+type panickingHandler struct {
+	BaseHandler
+}
+
+func (handler *panickingHandler) Handle(record *Record) {
+	panic("broken")
+}
+
+func TestTeeHandlerSync(t *testing.T) {
+	first, second := newRecordingHandler(), newRecordingHandler()
+	second.SetLevel(ErrorLevels)
+	handler := NewTeeHandler(first, second)
+	defer handler.Close()
+
+	handler.Handle(newTestRecord(INFO, "info"))
+	handler.Handle(newTestRecord(ERROR, "error"))
+
+	if messages := first.messages(); !equalStrings(messages, []string{"info", "error"}) {
+		t.Errorf("Unexpected first messages: %v", messages)
+	}
+	if messages := second.messages(); !equalStrings(messages, []string{"error"}) {
+		t.Errorf("Unexpected second messages: %v", messages)
+	}
+}
+
+func TestTeeHandlerIsolatesPanics(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewTeeHandler(&panickingHandler{}, target)
+	defer handler.Close()
+
+	var errs []error
+	handler.SetOnError(func(branch Handler, record *Record, err error) {
+		errs = append(errs, err)
+	})
+
+	handler.Handle(newTestRecord(INFO, "message"))
+
+	if messages := target.messages(); !equalStrings(messages, []string{"message"}) {
+		t.Errorf("Unexpected messages: %v", messages)
+	}
+	if len(errs) != 1 || handler.GetFailed() != 1 {
+		t.Errorf("Expected one failure, got %v and %d", errs, handler.GetFailed())
+	}
+}
+
+func TestTeeHandlerAsync(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewTeeHandler()
+	handler.AddHandler(target, TeeOptions{Async: true})
+
+	var wait sync.WaitGroup
+	for x := 0; x < 4; x++ {
+		wait.Add(1)
+		go func(x int) {
+			defer wait.Done()
+			for y := 0; y < 100; y++ {
+				handler.Handle(newTestRecord(INFO, fmt.Sprintf("%d-%d", x, y)))
+			}
+		}(x)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				handler.Flush()
+			}
+		}
+	}()
+
+	wait.Wait()
+	close(done)
+	handler.Flush()
+
+	if messages := target.messages(); len(messages) != 400 {
+		t.Errorf("Expected 400 messages, got %d", len(messages))
+	}
+	if err := handler.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTeeHandlerAsyncDropsWhenFull(t *testing.T) {
+	target := newBlockingHandler()
+	handler := NewTeeHandler()
+	handler.AddHandler(target, TeeOptions{Async: true, QueueSize: 2})
+
+	for x := 0; x < 10; x++ {
+		handler.Handle(newTestRecord(INFO, "message"))
+	}
+
+	if dropped := handler.GetDropped(); dropped < 7 {
+		t.Errorf("Expected at least 7 dropped records, got %d", dropped)
+	}
+
+	close(target.release)
+	handler.Flush()
+	if messages := target.messages(); len(messages) > 3 {
+		t.Errorf("Expected at most 3 messages, got %d", len(messages))
+	}
+	handler.Close()
+}
+
+func TestTeeHandlerHandleDuringClose(t *testing.T) {
+	target := newRecordingHandler()
+	handler := NewTeeHandler()
+	handler.AddHandler(target, TeeOptions{Async: true})
+
+	var wait sync.WaitGroup
+	wait.Add(1)
+	go func() {
+		defer wait.Done()
+		for x := 0; x < 1000; x++ {
+			handler.Handle(newTestRecord(INFO, "message"))
+		}
+	}()
+
+	handler.Close()
+	wait.Wait()
+}
+
+func TestTeeHandlerTimeoutDropsWhileStuck(t *testing.T) {
+	slow := newBlockingHandler()
+	fast := newRecordingHandler()
+	handler := NewTeeHandler(fast)
+	handler.AddHandler(slow, TeeOptions{Timeout: 10 * time.Millisecond})
+
+	var timeouts int
+	handler.SetOnError(func(branch Handler, record *Record, err error) {
+		timeouts++
+	})
+
+	for x := 0; x < 5; x++ {
+		handler.Handle(newTestRecord(INFO, "message"))
+	}
+
+	if timeouts != 1 || handler.GetDropped() != 4 {
+		t.Errorf("Expected 1 timeout and 4 drops, got %d and %d", timeouts, handler.GetDropped())
+	}
+	if messages := fast.messages(); len(messages) != 5 {
+		t.Errorf("Expected 5 messages on the fast branch, got %d", len(messages))
+	}
+
+	close(slow.release)
+	branch := handler.branches[1]
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		branch.Lock()
+		stuck := branch.stuck
+		branch.Unlock()
+		if stuck == 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	handler.Handle(newTestRecord(INFO, "recovered"))
+	if messages := slow.messages(); !equalStrings(messages, []string{"message", "recovered"}) {
+		t.Errorf("Unexpected slow messages: %v", messages)
+	}
+	handler.Close()
+}
+
+func TestFailoverHandler(t *testing.T) {
+	primary := &failingHandler{recordingHandler: *newRecordingHandler()}
+	secondary := newRecordingHandler()
+	handler := NewFailoverHandler(primary, secondary)
+	handler.SetRetryInterval(time.Hour)
+
+	var errs []error
+	handler.SetOnError(func(branch Handler, record *Record, err error) {
+		errs = append(errs, err)
+	})
+
+	handler.Handle(newTestRecord(INFO, "first"))
+	primary.setFail(true)
+	handler.Handle(newTestRecord(INFO, "second"))
+	primary.setFail(false)
+	handler.Handle(newTestRecord(INFO, "third"))
+
+	if messages := primary.messages(); !equalStrings(messages, []string{"first"}) {
+		t.Errorf("Unexpected primary messages: %v", messages)
+	}
+	if messages := secondary.messages(); !equalStrings(messages, []string{"second", "third"}) {
+		t.Errorf("Unexpected secondary messages: %v", messages)
+	}
+	if !handler.IsFailed() || len(errs) != 1 {
+		t.Errorf("Expected a failed primary and one error, got %v and %v", handler.IsFailed(), errs)
+	}
+
+	handler.SetRetryInterval(0)
+	handler.Handle(newTestRecord(INFO, "fourth"))
+	if messages := primary.messages(); !equalStrings(messages, []string{"first", "fourth"}) {
+		t.Errorf("Expected the primary to recover, got %v", messages)
+	}
+	if handler.IsFailed() {
+		t.Error("Expected the primary to be healthy")
+	}
+}
+
+func TestFailoverHandlerPrimaryLevel(t *testing.T) {
+	primary := &failingHandler{recordingHandler: *newRecordingHandler()}
+	secondary := newRecordingHandler()
+	handler := NewFailoverHandler(primary, secondary)
+	primary.SetLevel(ErrorLevels)
+
+	var errs []error
+	handler.SetOnError(func(branch Handler, record *Record, err error) {
+		errs = append(errs, err)
+	})
+
+	handler.Handle(newTestRecord(INFO, "info"))
+	handler.Handle(newTestRecord(ERROR, "error"))
+
+	if messages := primary.messages(); !equalStrings(messages, []string{"error"}) {
+		t.Errorf("Expected only records in the primary level, got %v", messages)
+	}
+	if messages := secondary.messages(); len(messages) != 0 || handler.IsFailed() || len(errs) != 0 {
+		t.Errorf("Expected no failover, got %v, %v and %v", messages, handler.IsFailed(), errs)
+	}
+}
+
+type failingHandler struct {
+	recordingHandler
+	fail bool
+}
+
+func (handler *failingHandler) setFail(fail bool) {
+	handler.Lock()
+	handler.fail = fail
+	handler.Unlock()
+}
+
+func (handler *failingHandler) TryHandle(record *Record) error {
+	handler.Lock()
+	fail := handler.fail
+	handler.Unlock()
+
+	if fail {
+		return errors.New("unavailable")
+	}
+
+	handler.recordingHandler.Handle(record)
+
+	return nil
+}